@@ -8,7 +8,9 @@ import (
 	"io"
 	"log"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/hatricker/alieninvasion/games"
 	"github.com/hatricker/alieninvasion/generators"
@@ -22,21 +24,67 @@ func main() {
 		cityMatrixY = flag.Int("my", 0, "size of y-coordinate of map matrix")
 		mapFile     = flag.String("mapfile", "", "Input map file")
 		outputFile  = flag.String("output", "", "output file to dump the map info")
+		cityNames   = flag.String("citynames", "", "file with city names, one per line or a JSON array (.json)")
+		alienNames  = flag.String("aliennames", "", "file with alien names, one per line or a JSON array (.json)")
+		extendNames = flag.Bool("extendnames", false, "add the names from -citynames and -aliennames to the built-in lists instead of replacing them")
+		order       = flag.String("order", "name", "order the aliens move and fight in each move, \"name\" or \"spawn\"")
+		moveMode    = flag.String("movemode", "sequential", "how the moves of one turn are applied, \"sequential\" or \"simultaneous\"")
+		crossing    = flag.String("crossing", "pass", "what aliens meeting head-on on a road do with simultaneous moves, \"pass\", \"fight\", \"cut\" (the road) or \"report\"")
+		policy      = flag.String("policy", "uniform", "how aliens pick their moves, \"uniform\", \"roads\", \"lazy:<stay chance>\", \"drift:<direction>:<bias>\", \"avoid:<memory>\", \"seek[:<radius>]\" or \"flee[:<radius>]\"")
+		alienPolicy = flag.String("alienpolicy", "", "comma separated <alien>=<policy> pairs overriding -policy for single aliens")
+		collision   = flag.String("collision", "mutual", "what aliens meeting in a city do, \"mutual\", \"survivor\", \"brawl\", \"raid\" or \"combat\", optionally followed by the number of aliens needed, e.g. \"mutual:3\"")
+		alienStats  = flag.String("alienstats", "", "comma separated <alien>=<health>/<strength>/<armour> attributes used by the \"combat\" collision rule, other aliens get random ones")
+		factions    = flag.Int("factions", 0, "split the aliens into this many factions taking turns, aliens of the same faction do not fight each other")
+		factionOf   = flag.String("alienfaction", "", "comma separated <alien>=<faction> pairs putting single aliens in a faction")
+		scenario    = flag.String("scenario", "", "JSON file setting up the game, e.g. {\"factions\": {\"red\": [\"Zidane\"]}}")
+		defense     = flag.Int("defense", 0, "give each city without a defense in the map a random one within 0-<defense>, the number of attacks it repels before it falls")
+		decay       = flag.Int("defensedecay", 10, "number of moves after which the defense of every city drops by one, 0 for never")
+		numHumans   = flag.Int("nh", 0, "Number of human defenders, they kill a lone alien they meet and die when outnumbered")
+		humanPolicy = flag.String("humanpolicy", "roads", "how humans pick their moves, takes the same values as -policy")
+		waves       = flag.String("waves", "", "comma separated reinforcement waves, <count>:<every>[:<placement>], e.g. \"5:50:edge\" lands 5 aliens every 50 moves in \"edge\" or \"random\" cities")
+		reproduce   = flag.String("reproduce", "", "let aliens spawn offspring into the next cities, comma separated \"age=<moves>\", \"undamaged\", \"cooldown=<moves>\" and \"cap=<aliens>\", e.g. \"age=10,cap=50\"")
+		energy      = flag.String("energy", "", "make aliens spend energy moving, comma separated \"capacity=<energy>\", \"cost=<energy per move>\", \"recharge=<energy per move>\" and \"die\", e.g. \"capacity=20,recharge=1\"")
+		mapEvents   = flag.String("mapevents", "", "comma separated random map changes, <kind>:<chance per move> or <kind>:every=<moves> for kinds \"collapse\", \"meteor\" and \"road\", e.g. \"collapse:0.05,meteor:every=20\"")
+		fire        = flag.String("fire", "", "let destroyed cities burn and set the cities next to them on fire, comma separated \"chance=<0-1 per move>\" and \"burn=<moves>\", e.g. \"chance=0.2,burn=3\"")
+		rebuild     = flag.String("rebuild", "", "rebuild destroyed cities left without aliens, comma separated \"after=<moves>\" and \"chance=<0-1 per road>\", e.g. \"after=20,chance=0.5\"")
+		stalemate   = flag.Int("stalemate", 0, "end the game once this many moves went by without anything happening, 0 for never")
+		seed        = flag.Int64("seed", 0, "seed of the random generator, the same seed and map give the same game (default: random)")
 	)
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [-na <number of aliens> -mx <X> -my <Y> -mapfile <input map file> -output <output map file>]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [-na <number of aliens> -mx <X> -my <Y> -mapfile <input map file> -output <output map file> -citynames <names file> -aliennames <names file>]\n", os.Args[0])
 
 		flag.PrintDefaults()
 	}
 	flag.Parse()
 
+	var err error
+	if *cityNames != "" {
+		generators.CityNames, err = loadNames(*cityNames, generators.CityNames, *extendNames, generators.ValidateCityName)
+		if err != nil {
+			log.Fatalf("cannot load city names, %v", err)
+		}
+	}
+	if *alienNames != "" {
+		generators.AlienNames, err = loadNames(*alienNames, generators.AlienNames, *extendNames, generators.ValidateAlienName)
+		if err != nil {
+			log.Fatalf("cannot load alien names, %v", err)
+		}
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	log.Printf("Random seed: %d", *seed)
+	rng := generators.NewSeededNumGen(*seed)
+
 	//when outputFile is given, just dump the generated city map
 	if *outputFile != "" {
-		cityMap, err := generateMap(*cityMatrixX, *cityMatrixY)
+		cityMap, err := generateMap(*cityMatrixX, *cityMatrixY, rng.Stream("generation"))
 		if err != nil {
 			log.Fatalf("cannot generate map, %v", err)
 		}
+		generators.GenerateDefenses(cityMap, *defense, rng.Stream("defense"))
 		dumpMapIntoFile(cityMap, *outputFile)
 		return
 	}
@@ -48,20 +96,128 @@ func main() {
 		log.Fatalln("Number of Aliens must be greater than 0")
 	}
 
-	if err := playGame(*mapFile, *numAliens, *numMoves, *cityMatrixX, *cityMatrixY); err != nil {
+	ordering, err := games.ParseOrdering(*order)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	mode, err := games.ParseMoveMode(*moveMode)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	crossingRule, err := games.ParseCrossingRule(*crossing)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	movePolicy, err := games.ParsePolicy(*policy)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	alienPolicies, err := games.ParseAlienPolicies(*alienPolicy)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	collisionRule, err := games.ParseCollisionRule(*collision)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	reinforcements, err := games.ParseWaves(*waves)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	reproduction, err := games.ParseReproduction(*reproduce)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	fuel, err := games.ParseEnergy(*energy)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	changes, err := games.ParseMapEvents(*mapEvents)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	spread, err := games.ParseFire(*fire)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	rebuilding, err := games.ParseRebuild(*rebuild)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	humanMoves, err := games.ParsePolicy(*humanPolicy)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if *numHumans < 0 {
+		log.Fatalln("Number of humans cannot be negative")
+	}
+	attributes, err := games.ParseAlienAttributes(*alienStats)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	alienFactions, err := games.ParseFactions(*factionOf)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if *factions < 0 {
+		log.Fatalln("Number of factions cannot be negative")
+	}
+	if *defense < 0 {
+		log.Fatalln("City defense cannot be negative")
+	}
+	if *stalemate < 0 {
+		log.Fatalln("Stalemate moves cannot be negative")
+	}
+	opts := []games.Option{
+		games.WithOrdering(ordering),
+		games.WithMoveMode(mode),
+		games.WithCrossingRule(crossingRule),
+		games.WithPolicy(movePolicy),
+		games.WithCollisionRule(collisionRule),
+		games.WithDefenseDecay(*decay),
+		games.WithHumanPolicy(humanMoves),
+		games.WithWaves(reinforcements...),
+		games.WithReproduction(reproduction),
+		games.WithEnergy(fuel),
+		games.WithMapEvents(changes...),
+		games.WithFire(spread),
+		games.WithRebuild(rebuilding),
+		games.WithStalemate(*stalemate),
+	}
+	for i := 1; i <= *numHumans; i++ {
+		opts = append(opts, games.WithHumans(fmt.Sprintf("Human%d", i)))
+	}
+	for alien, p := range alienPolicies {
+		opts = append(opts, games.WithAlienPolicy(alien, p))
+	}
+	for alien, a := range attributes {
+		opts = append(opts, games.WithAlien(alien, a))
+	}
+	if *scenario != "" {
+		s, err := loadScenario(*scenario)
+		if err != nil {
+			log.Fatalf("cannot load scenario, %v", err)
+		}
+		opts = append(opts, s.Options()...)
+	}
+	for alien, faction := range alienFactions {
+		opts = append(opts, games.WithFaction(alien, faction))
+	}
+
+	if err := playGame(*mapFile, *numAliens, *numMoves, *cityMatrixX, *cityMatrixY, *factions, *defense, rng, opts...); err != nil {
 		log.Fatalf("Error happened when running the game: %v", err)
 	}
 }
 
-func generateMap(x, y int) (map[string]*generators.CityNode, error) {
+func generateMap(x, y int, rng *generators.SeededNumGen) (map[string]*generators.CityNode, error) {
 	if x == 0 || y == 0 {
 		return nil, fmt.Errorf("need to provide both city matrix x and y")
 	}
-	masks, err := generators.GenerateDirectionMask(x, y, generators.RandNumGenerator)
+	masks, err := generators.GenerateDirectionMask(x, y, rng)
 	if err != nil {
 		return nil, fmt.Errorf("cannot generate city map matrix masks, %v", err)
 	}
-	cityNames, err := generators.GenerateCityNames(generators.RandNumArrGenerator, x*y)
+	cityNames, err := generators.GenerateCityNames(rng, x*y)
 	if err != nil {
 		return nil, fmt.Errorf("cannot generate city names, %v", err)
 	}
@@ -78,9 +234,48 @@ func dumpMapIntoFile(cm map[string]*generators.CityNode, fileName string) error
 	return nil
 }
 
+//loadNames reads the names in fileName and validates them. The result
+//replaces the built-in names, or extends them when extend is set
+func loadNames(fileName string, builtin []string, extend bool, validate func(string) error) ([]string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	names, err := generators.ReadNames(f, strings.HasSuffix(strings.ToLower(fileName), ".json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if err := validate(name); err != nil {
+			return nil, err
+		}
+	}
+	if extend {
+		return generators.MergeNames(builtin, names), nil
+	}
+	if names = generators.MergeNames(nil, names); len(names) == 0 {
+		return nil, fmt.Errorf("no names found in %s", fileName)
+	}
+	return names, nil
+}
+
+//loadScenario reads the scenario in fileName
+func loadScenario(fileName string) (*games.Scenario, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return games.LoadScenario(f)
+}
+
 //Obtain the map either by generating it on the fly or taking from a local file,
-//then start the game
-func playGame(mapFile string, numAliens, numMoves, x, y int) error {
+//then start the game. When numFactions is set, the aliens take turns joining
+//the factions, explicit factions in opts win over it. Cities without a
+//defense get a random one within 0-maxDefense
+func playGame(mapFile string, numAliens, numMoves, x, y, numFactions, maxDefense int, rng *generators.SeededNumGen, opts ...games.Option) error {
 	var (
 		cityMap map[string]*generators.CityNode
 		err     error
@@ -88,7 +283,7 @@ func playGame(mapFile string, numAliens, numMoves, x, y int) error {
 
 	//if no map file is provided, generate a map automatically
 	if mapFile == "" {
-		if cityMap, err = generateMap(x, y); err != nil {
+		if cityMap, err = generateMap(x, y, rng.Stream("generation")); err != nil {
 			return fmt.Errorf("cannot generate map, %v", err)
 		}
 	} else {
@@ -101,29 +296,114 @@ func playGame(mapFile string, numAliens, numMoves, x, y int) error {
 
 		scanner := bufio.NewScanner(f)
 		cityMap = generators.GenerateCityMapFromSteam(scanner, ' ')
+		if err := generators.ValidateCityMap(cityMap); err != nil {
+			return fmt.Errorf("cannot use map file %s, %v", mapFile, err)
+		}
 	}
 
+	generators.GenerateDefenses(cityMap, maxDefense, rng.Stream("defense"))
+
 	//Initial map is printed to Stderr along with other logs
 	log.Println("Obtained city map...")
 	printCityMap(cityMap, os.Stderr)
 
-	aliens, err := generators.GenerateAlienNames(generators.RandNumArrGenerator, numAliens)
+	if numAliens > len(cityMap) {
+		return fmt.Errorf("cannot place %d aliens on %d cities, %v", numAliens, len(cityMap), generators.ErrReqTooLarge)
+	}
+
+	aliens, err := generators.GenerateAlienNames(rng.Stream("names"), numAliens)
 	if err != nil {
 		return fmt.Errorf("cannot generate alien names, %v", err)
 	}
 
 	log.Printf("Generated aliens: %s", strings.Join(aliens, " "))
 
-	g := games.NewGame(aliens, cityMap, generators.RandNumGenerator)
+	if numFactions > 0 {
+		teams := make([]games.Option, 0, len(aliens))
+		for i, alien := range aliens {
+			teams = append(teams, games.WithFaction(alien, fmt.Sprintf("faction%d", i%numFactions+1)))
+		}
+		opts = append(teams, opts...)
+	}
+
+	g := games.NewGame(aliens, cityMap, rng, opts...)
 	log.Println("Game starting...")
-	g.StartGame(numMoves)
+	ending, moves := g.StartGame(numMoves)
 
 	//Map at the end is printed to Stdout solely which could be redirected to a file
+	//The seed goes on top as a comment, so the file can still be used as a map
+	log.Printf("Game over after %d moves, %v, %d aliens survived, seed %d", moves, ending, len(g.AlienLocations), rng.Seed())
+	if destroyed := g.DestroyedCities(); len(destroyed) > 0 {
+		log.Printf("%d cities lie destroyed: %s", len(destroyed), strings.Join(destroyed, " "))
+	}
+	if humans := g.Humans(); len(humans) > 0 {
+		log.Printf("%d of %d humans survived", len(g.HumanLocations), len(humans))
+	}
+	printEvents(g)
+	printFactions(g)
 	log.Println("Printing city map at the end of game...")
+	fmt.Fprintf(os.Stdout, "# seed %d\n", rng.Seed())
 	printCityMap(g.CityMap, os.Stdout)
 	return nil
 }
 
+//printEvents logs city destructions, fights, repelled attacks, human defenses,
+//reinforcements, offspring, aliens out of energy or on the road, map changes,
+//rebuilt cities and road crossings separately
+func printEvents(g *games.Game) {
+	for _, kind := range []struct {
+		kind games.EventKind
+		name string
+	}{
+		{games.CityDestroyed, "cities destroyed"},
+		{games.CityFight, "fights in cities left standing"},
+		{games.CityDefended, "attacks repelled"},
+		{games.HumanDefense, "fights between humans and aliens"},
+		{games.Reinforcement, "reinforcement waves"},
+		{games.Offspring, "offspring spawned"},
+		{games.OutOfEnergy, "aliens out of energy"},
+		{games.DiedOnRoad, "aliens died on the road"},
+		{games.RoadCollapsed, "roads collapsed"},
+		{games.RoadOpened, "roads opened"},
+		{games.CityRebuilt, "cities rebuilt"},
+		{games.RoadCrossing, "road crossings"},
+	} {
+		events := g.EventsOf(kind.kind)
+		log.Printf("%d %s", len(events), kind.name)
+		for _, e := range events {
+			log.Printf("  %v", e)
+		}
+	}
+}
+
+//printFactions logs the survivors of each faction, when there are factions
+func printFactions(g *games.Game) {
+	if len(g.Factions) == 0 {
+		return
+	}
+	byFaction := g.SurvivorsByFaction()
+	factions := make(map[string]bool)
+	for _, faction := range g.Factions {
+		factions[faction] = true
+	}
+	for faction := range byFaction {
+		factions[faction] = true
+	}
+	names := make([]string, 0, len(factions))
+	for faction := range factions {
+		names = append(names, faction)
+	}
+	sort.Strings(names)
+	log.Println("Survivors per faction:")
+	for _, faction := range names {
+		name := faction
+		if name == "" {
+			name = "(no faction)"
+		}
+		log.Printf("  %s: %d %s", name, len(byFaction[faction]), strings.Join(byFaction[faction], " "))
+	}
+}
+
 //print city map to the stdout
 func printCityMap(cm map[string]*generators.CityNode, w io.Writer) {
 	var b bytes.Buffer
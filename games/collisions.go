@@ -0,0 +1,114 @@
+package games
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hatricker/alieninvasion/generators"
+)
+
+//CollisionRule decides what happens when aliens meet in a city
+//Resolve gets the living aliens in the city and returns the ones which die
+//and whether the city is destroyed. gen is the game's collision stream
+type CollisionRule interface {
+	Resolve(g *Game, city string, aliens []string, gen generators.NumGen) (dead []string, destroy bool)
+}
+
+//MutualDestructionRule kills all aliens and destroys the city when at least
+//Threshold aliens meet in it. With a Threshold of 2 it is the default rule
+type MutualDestructionRule struct {
+	Threshold int
+}
+
+//Resolve implements CollisionRule interface
+func (r *MutualDestructionRule) Resolve(_ *Game, _ string, aliens []string, _ generators.NumGen) ([]string, bool) {
+	if len(aliens) < threshold(r.Threshold) {
+		return nil, false
+	}
+	return aliens, true
+}
+
+//SurvivorRule makes the aliens fight when at least Threshold of them meet,
+//leaving one random survivor. The city is destroyed unless KeepCity is set
+type SurvivorRule struct {
+	Threshold int
+	KeepCity  bool
+}
+
+//Resolve implements CollisionRule interface
+func (r *SurvivorRule) Resolve(_ *Game, _ string, aliens []string, gen generators.NumGen) ([]string, bool) {
+	if len(aliens) < threshold(r.Threshold) {
+		return nil, false
+	}
+	survivor := gen.GenerateNum(len(aliens))
+	dead := make([]string, 0, len(aliens)-1)
+	dead = append(dead, aliens[:survivor]...)
+	return append(dead, aliens[survivor+1:]...), !r.KeepCity
+}
+
+//RaidRule makes at least Threshold aliens meeting in a city destroy it
+//without fighting each other, so all of them survive
+type RaidRule struct {
+	Threshold int
+}
+
+//Resolve implements CollisionRule interface
+func (r *RaidRule) Resolve(_ *Game, _ string, aliens []string, _ generators.NumGen) ([]string, bool) {
+	return nil, len(aliens) >= threshold(r.Threshold)
+}
+
+//threshold returns the number of aliens needed for a collision,
+//which is 2 unless set otherwise
+func threshold(n int) int {
+	if n < 1 {
+		return 2
+	}
+	return n
+}
+
+//WithCollisionRule sets what happens when aliens meet in a city
+func WithCollisionRule(rule CollisionRule) Option {
+	return func(g *Game) {
+		g.collision = rule
+	}
+}
+
+//collisionRule returns the game's collision rule
+func (g *Game) collisionRule() CollisionRule {
+	if g.collision != nil {
+		return g.collision
+	}
+	return &MutualDestructionRule{Threshold: 2}
+}
+
+//ParseCollisionRule builds a collision rule from its description:
+//"mutual", "survivor", "brawl" (a survivor and the city stands), "raid" or
+//"combat" (see CombatRule), optionally followed by the number of aliens
+//needed, e.g. "mutual:3"
+func ParseCollisionRule(spec string) (CollisionRule, error) {
+	fields := strings.Split(spec, ":")
+	n := 2
+	if len(fields) > 2 {
+		return nil, fmt.Errorf("unknown collision rule %q", spec)
+	}
+	if len(fields) == 2 {
+		var err error
+		if n, err = strconv.Atoi(fields[1]); err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid number of aliens %q in collision rule %q", fields[1], spec)
+		}
+	}
+	switch fields[0] {
+	case "mutual":
+		return &MutualDestructionRule{Threshold: n}, nil
+	case "survivor":
+		return &SurvivorRule{Threshold: n}, nil
+	case "brawl":
+		return &SurvivorRule{Threshold: n, KeepCity: true}, nil
+	case "raid":
+		return &RaidRule{Threshold: n}, nil
+	case "combat":
+		return &CombatRule{Threshold: n}, nil
+	}
+	return nil, fmt.Errorf("unknown collision rule %q", spec)
+}
@@ -0,0 +1,105 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/hatricker/alieninvasion/generators"
+	"github.com/stretchr/testify/assert"
+)
+
+//generateCrowdedGame puts num aliens in the first testing city
+func generateCrowdedGame(num int, opts ...Option) (*Game, []string) {
+	aliens := generators.AlienNames[:num]
+	cityMap := generateCityMap()
+	game := NewGame(nil, cityMap, fakeZeroGenerator, opts...)
+	for _, alien := range aliens {
+		game.AlienLocations[alien] = testingCityNames[0]
+		cityMap[testingCityNames[0]].Aliens = append(cityMap[testingCityNames[0]].Aliens, alien)
+	}
+	return game, aliens
+}
+
+func TestCollisionRules(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		rule      CollisionRule
+		aliens    int
+		survivors int
+		destroyed bool
+		outcome   string
+	}{
+		{nil, 2, 0, true, ""},
+		{&MutualDestructionRule{Threshold: 3}, 2, 2, false, ""},
+		{&MutualDestructionRule{Threshold: 3}, 3, 0, true, ""},
+		{&SurvivorRule{}, 3, 1, true, "Yalmimin survived"},
+		{&SurvivorRule{KeepCity: true}, 3, 1, false, "Yalmimin survived"},
+		{&SurvivorRule{Threshold: 4}, 3, 3, false, ""},
+		{&RaidRule{}, 2, 2, true, "no alien died"},
+		{&RaidRule{Threshold: 1}, 1, 1, true, "no alien died"},
+	}
+
+	for _, tt := range tests {
+		var opts []Option
+		if tt.rule != nil {
+			opts = append(opts, WithCollisionRule(tt.rule))
+		}
+		game, _ := generateCrowdedGame(tt.aliens, opts...)
+		game.CheckAndDestroy()
+
+		assert.Equal(tt.survivors, len(game.AlienLocations))
+		node := game.CityMap[testingCityNames[0]]
+		assert.Equal(tt.destroyed, node.East == nil && node.South == nil)
+		assert.Equal(tt.destroyed, game.IsDestroyed(testingCityNames[0]))
+		if tt.survivors == tt.aliens {
+			assert.Empty(game.EventsOf(CityFight))
+			if !tt.destroyed {
+				continue
+			}
+		}
+		assert.Equal(1, len(game.Events))
+		assert.Equal(tt.outcome, game.Events[0].Outcome)
+	}
+}
+
+func TestCollisionInDestroyedCity(t *testing.T) {
+	assert := assert.New(t)
+
+	game, aliens := generateCrowdedGame(2, WithCollisionRule(&RaidRule{}))
+	game.CheckAndDestroy()
+	game.CheckAndDestroy()
+	assert.Equal(1, len(game.EventsOf(CityDestroyed)))
+
+	//a fight in a destroyed city still kills, but cannot destroy it again
+	game.collision = &SurvivorRule{}
+	game.CheckAndDestroy()
+	assert.Equal([]string{aliens[0]}, game.livingAliensIn(testingCityNames[0]))
+	assert.Equal(1, len(game.EventsOf(CityDestroyed)))
+	assert.Equal(1, len(game.EventsOf(CityFight)))
+}
+
+func TestParseCollisionRule(t *testing.T) {
+	assert := assert.New(t)
+
+	valid := []struct {
+		spec string
+		rule CollisionRule
+	}{
+		{"mutual", &MutualDestructionRule{Threshold: 2}},
+		{"mutual:3", &MutualDestructionRule{Threshold: 3}},
+		{"survivor", &SurvivorRule{Threshold: 2}},
+		{"brawl:4", &SurvivorRule{Threshold: 4, KeepCity: true}},
+		{"raid:1", &RaidRule{Threshold: 1}},
+		{"combat:3", &CombatRule{Threshold: 3}},
+	}
+	for _, tt := range valid {
+		rule, err := ParseCollisionRule(tt.spec)
+		assert.Nil(err, tt.spec)
+		assert.Equal(tt.rule, rule, tt.spec)
+	}
+
+	for _, spec := range []string{"", "mutual:0", "raid:x", "raid:2:3", "peace"} {
+		_, err := ParseCollisionRule(spec)
+		assert.NotNil(err, spec)
+	}
+}
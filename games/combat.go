@@ -0,0 +1,146 @@
+package games
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hatricker/alieninvasion/generators"
+)
+
+//Alien holds the combat attributes of an alien
+//Health drops by the damage taken in fights and the alien dies when it
+//reaches 0. Strength is the damage of a hit before Armour of the one hit
+//takes its share
+//Energy is what the alien has left to move with, see Energy
+type Alien struct {
+	Health   int
+	Strength int
+	Armour   int
+	Energy   int
+}
+
+//WithAlien sets the attributes of an alien. Aliens without attributes set
+//get random ones when the game starts
+func WithAlien(name string, attributes Alien) Option {
+	return func(g *Game) {
+		if g.Aliens == nil {
+			g.Aliens = make(map[string]*Alien)
+		}
+		a := attributes
+		g.Aliens[name] = &a
+	}
+}
+
+//randomAlien returns random attributes, Health within 50-100,
+//Strength within 5-20 and Armour within 0-5
+func randomAlien(gen generators.NumGen) *Alien {
+	return &Alien{
+		Health:   50 + gen.GenerateNum(51),
+		Strength: 5 + gen.GenerateNum(16),
+		Armour:   gen.GenerateNum(6),
+	}
+}
+
+//assignAttributes gives random attributes to the aliens which have none
+//and a full charge of energy to the ones without energy
+func (g *Game) assignAttributes(aliens []string) {
+	if g.Aliens == nil {
+		g.Aliens = make(map[string]*Alien)
+	}
+	for _, alien := range aliens {
+		if _, ok := g.Aliens[alien]; !ok {
+			g.Aliens[alien] = randomAlien(g.alienStream("attributes", alien))
+		}
+	}
+	g.fillEnergy(aliens)
+}
+
+//CombatRule makes at least Threshold aliens meeting in a city fight using
+//their attributes. In each round every alien hits another random alien,
+//until at most one is left standing. The winner keeps its damaged health.
+//All damage dealt also hits the city, which is destroyed once its total
+//damage reaches CityHealth (100 when not set)
+type CombatRule struct {
+	Threshold  int
+	CityHealth int
+}
+
+//Resolve implements CollisionRule interface
+func (r *CombatRule) Resolve(g *Game, city string, aliens []string, gen generators.NumGen) ([]string, bool) {
+	if len(aliens) < threshold(r.Threshold) {
+		return nil, false
+	}
+	fighters := make(map[string]*Alien, len(aliens))
+	for _, alien := range aliens {
+		if _, ok := g.Aliens[alien]; !ok {
+			g.assignAttributes([]string{alien})
+		}
+		fighters[alien] = g.Aliens[alien]
+	}
+
+	total := 0
+	standing := append([]string{}, aliens...)
+	for len(standing) > 1 {
+		for _, attacker := range standing {
+			target := standing[gen.GenerateNum(len(standing)-1)]
+			if target == attacker {
+				target = standing[len(standing)-1]
+			}
+			damage := fighters[attacker].Strength - fighters[target].Armour
+			if damage < 1 {
+				damage = 1
+			}
+			fighters[target].Health -= damage
+			total += damage
+		}
+		left := standing[:0]
+		for _, alien := range standing {
+			if fighters[alien].Health > 0 {
+				left = append(left, alien)
+			}
+		}
+		standing = left
+	}
+
+	node := g.CityMap[city]
+	node.Damage += total
+	cityHealth := r.CityHealth
+	if cityHealth <= 0 {
+		cityHealth = 100
+	}
+
+	dead := make([]string, 0, len(aliens))
+	for _, alien := range aliens {
+		if len(standing) == 0 || alien != standing[0] {
+			dead = append(dead, alien)
+		}
+	}
+	return dead, node.Damage >= cityHealth
+}
+
+//ParseAlienAttributes parses a comma separated list of
+//<alien>=<health>/<strength>/<armour>, e.g. "Zidane=120/15/5"
+func ParseAlienAttributes(spec string) (map[string]Alien, error) {
+	attributes := make(map[string]Alien)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		i := strings.LastIndex(pair, "=")
+		values := strings.Split(pair[i+1:], "/")
+		if i <= 0 || len(values) != 3 {
+			return nil, fmt.Errorf("invalid alien attributes %q, expecting <alien>=<health>/<strength>/<armour>", pair)
+		}
+		numbers := make([]int, 3)
+		for j, value := range values {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 || (j == 0 && n == 0) {
+				return nil, fmt.Errorf("invalid alien attributes %q", pair)
+			}
+			numbers[j] = n
+		}
+		attributes[strings.TrimSpace(pair[:i])] = Alien{Health: numbers[0], Strength: numbers[1], Armour: numbers[2]}
+	}
+	return attributes, nil
+}
@@ -0,0 +1,75 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/hatricker/alieninvasion/generators"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombatRule(t *testing.T) {
+	assert := assert.New(t)
+
+	aliens := generators.AlienNames[:2]
+	tests := []struct {
+		rule      *CombatRule
+		destroyed bool
+	}{
+		{&CombatRule{}, false},
+		{&CombatRule{CityHealth: 20}, true},
+	}
+
+	for _, tt := range tests {
+		game, _ := generateCrowdedGame(2, WithCollisionRule(tt.rule),
+			WithAlien(aliens[0], Alien{Health: 30, Strength: 20}),
+			WithAlien(aliens[1], Alien{Health: 10, Strength: 5, Armour: 2}))
+		game.CheckAndDestroy()
+
+		assert.Equal([]string{aliens[0]}, game.livingAliensIn(testingCityNames[0]))
+		assert.Equal(25, game.Aliens[aliens[0]].Health)
+		assert.Equal(23, game.CityMap[testingCityNames[0]].Damage)
+		assert.Equal(tt.destroyed, game.IsDestroyed(testingCityNames[0]))
+	}
+}
+
+func TestCombatRuleBelowThreshold(t *testing.T) {
+	assert := assert.New(t)
+
+	game, _ := generateCrowdedGame(2, WithCollisionRule(&CombatRule{Threshold: 3}))
+	game.CheckAndDestroy()
+	assert.Equal(2, len(game.AlienLocations))
+	assert.Equal(0, game.CityMap[testingCityNames[0]].Damage)
+	assert.Empty(game.Events)
+}
+
+func TestRandomAttributes(t *testing.T) {
+	assert := assert.New(t)
+
+	aliens := generators.AlienNames[:3]
+	game := NewGame(aliens, generateCityMap(), generators.NewSeededNumGen(7),
+		WithAlien(aliens[0], Alien{Health: 1}))
+	assert.Equal(len(aliens), len(game.Aliens))
+	assert.Equal(Alien{Health: 1}, *game.Aliens[aliens[0]])
+	for _, alien := range aliens[1:] {
+		a := game.Aliens[alien]
+		assert.True(a.Health >= 50 && a.Health <= 100)
+		assert.True(a.Strength >= 5 && a.Strength <= 20)
+		assert.True(a.Armour >= 0 && a.Armour <= 5)
+	}
+}
+
+func TestParseAlienAttributes(t *testing.T) {
+	assert := assert.New(t)
+
+	attributes, err := ParseAlienAttributes("Zidane=120/15/5, Salah=60/8/0")
+	assert.Nil(err)
+	assert.Equal(map[string]Alien{
+		"Zidane": {Health: 120, Strength: 15, Armour: 5},
+		"Salah":  {Health: 60, Strength: 8},
+	}, attributes)
+
+	for _, spec := range []string{"Zidane", "Zidane=1/2", "=1/2/3", "Zidane=0/1/1", "Zidane=1/-1/1", "Zidane=a/1/1"} {
+		_, err := ParseAlienAttributes(spec)
+		assert.NotNil(err, spec)
+	}
+}
@@ -0,0 +1,42 @@
+package games
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+//WithDefenseDecay makes the defense of every city drop by one each
+//given number of moves. Defenses do not decay when it is not positive
+func WithDefenseDecay(moves int) Option {
+	return func(g *Game) {
+		g.defenseDecay = moves
+	}
+}
+
+//repel uses up one defense of the city to stop aliens destroying it.
+//The aliens killed in the attack stay dead
+func (g *Game) repel(city string, aliens, dead []string) {
+	node := g.CityMap[city]
+	node.Defense--
+	log.Printf("!!!!!!City %s repelled aliens: %s, %d defense left !!!!!!",
+		city, strings.Join(aliens, " "), node.Defense)
+	outcome := fmt.Sprintf("%d defense left", node.Defense)
+	if alive := survivors(aliens, dead); alive != "" {
+		outcome = alive + ", " + outcome
+	}
+	g.record(CityDefended, []string{city}, aliens, outcome)
+}
+
+//decayDefenses lowers the defense of all cities by one every
+//defenseDecay moves
+func (g *Game) decayDefenses() {
+	if g.defenseDecay <= 0 || (g.turn+1)%g.defenseDecay != 0 {
+		return
+	}
+	for _, node := range g.CityMap {
+		if node.Defense > 0 {
+			node.Defense--
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefenseRepelsAttacks(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		rule      CollisionRule
+		survivors int
+		outcome   string
+	}{
+		{nil, 0, "1 defense left"},
+		{&SurvivorRule{}, 1, "Yalmimin survived, 1 defense left"},
+		{&RaidRule{}, 2, "no alien died, 1 defense left"},
+	}
+
+	for _, tt := range tests {
+		var opts []Option
+		if tt.rule != nil {
+			opts = append(opts, WithCollisionRule(tt.rule))
+		}
+		game, _ := generateCrowdedGame(2, opts...)
+		node := game.CityMap[testingCityNames[0]]
+		node.Defense = 2
+		game.CheckAndDestroy()
+
+		assert.Equal(tt.survivors, len(game.AlienLocations))
+		assert.Equal(1, node.Defense)
+		assert.NotNil(node.East)
+		assert.False(game.IsDestroyed(testingCityNames[0]))
+		assert.Equal(1, len(game.EventsOf(CityDefended)))
+		assert.Equal(tt.outcome, game.Events[0].Outcome)
+	}
+
+	game, _ := generateCrowdedGame(2, WithCollisionRule(&RaidRule{}))
+	game.CityMap[testingCityNames[0]].Defense = 1
+	game.CheckAndDestroy()
+	game.CheckAndDestroy()
+	assert.Equal(1, len(game.EventsOf(CityDefended)))
+	assert.True(game.IsDestroyed(testingCityNames[0]))
+}
+
+func TestDefenseDecay(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, decay := range []int{0, 2} {
+		game := generateGame()
+		WithDefenseDecay(decay)(game)
+		node := game.CityMap[testingCityNames[1]]
+		node.Defense = 3
+		for turn := 0; turn < 4; turn++ {
+			game.turn = turn
+			game.decayDefenses()
+		}
+		if decay == 0 {
+			assert.Equal(3, node.Defense)
+		} else {
+			assert.Equal(1, node.Defense)
+		}
+	}
+}
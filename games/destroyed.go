@@ -0,0 +1,79 @@
+package games
+
+import (
+	"log"
+	"sort"
+)
+
+//Destruction records how a city was destroyed. Turn is the move it happened
+//at and Aliens are the aliens responsible, none when something else caused
+//it. Cause is "aliens", "meteor", "fire from <city>", or empty for a city
+//destroyed with DestroyCity
+type Destruction struct {
+	Turn   int
+	Aliens []string
+	Cause  string
+}
+
+//destroy marks the city destroyed by the aliens responsible or by cause,
+//then cuts it off the map, see cutOff. The aliens on the roads leaving the
+//city die along with the roads. It tells whether the city was destroyed,
+//a city destroyed already cannot be destroyed again
+func (g *Game) destroy(city string, aliens []string, cause string) bool {
+	if g.IsDestroyed(city) {
+		return false
+	}
+	if g.Destroyed == nil {
+		g.Destroyed = make(map[string]*Destruction)
+	}
+	g.Destroyed[city] = &Destruction{Turn: g.turn, Aliens: append([]string{}, aliens...), Cause: cause}
+	for _, alien := range g.alienNames() {
+		transit, ok := g.Transits[alien]
+		if !ok || transit.From != city {
+			continue
+		}
+		delete(g.Transits, alien)
+		delete(g.AlienLocations, alien)
+		log.Printf("!!!!!!Alien %s died on the road from %s to %s !!!!!!", alien, city, transit.To)
+		g.record(DiedOnRoad, []string{city, transit.To}, []string{alien}, "road cut off")
+	}
+	g.cutOff(city)
+	return true
+}
+
+//wipeOut destroys the city along with every living alien in it, for a
+//cause other than aliens
+func (g *Game) wipeOut(city, cause string) {
+	aliens := g.livingAliensIn(city)
+	for _, alien := range aliens {
+		delete(g.AlienLocations, alien)
+	}
+	g.record(CityDestroyed, []string{city}, aliens, cause)
+	g.destroy(city, nil, cause)
+}
+
+//IsDestroyed tells whether the city was destroyed earlier in the game,
+//and not rebuilt since
+func (g *Game) IsDestroyed(city string) bool {
+	_, ok := g.Destroyed[city]
+	return ok
+}
+
+//DestructionOf returns how the city was destroyed, and false when it stands
+func (g *Game) DestructionOf(city string) (Destruction, bool) {
+	d, ok := g.Destroyed[city]
+	if !ok {
+		return Destruction{}, false
+	}
+	return *d, true
+}
+
+//DestroyedCities returns the names of the destroyed cities in sorted order
+func (g *Game) DestroyedCities() []string {
+	cities := make([]string, 0, len(g.Destroyed))
+	for city := range g.Destroyed {
+		cities = append(cities, city)
+	}
+	sort.Strings(cities)
+	return cities
+}
@@ -0,0 +1,126 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/hatricker/alieninvasion/generators"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDestroyedCities(t *testing.T) {
+	assert := assert.New(t)
+
+	game, aliens := generateCrowdedGame(2)
+	game.turn = 5
+	game.CheckAndDestroy()
+	assert.True(game.IsDestroyed(testingCityNames[0]))
+	d, ok := game.DestructionOf(testingCityNames[0])
+	assert.True(ok)
+	assert.Equal(Destruction{Turn: 5, Aliens: aliens, Cause: "aliens"}, d)
+
+	//a destroyed city cannot be destroyed again
+	game.turn = 6
+	game.DestroyCity(testingCityNames[0])
+	assert.False(game.destroy(testingCityNames[0], nil, "meteor"))
+	d, _ = game.DestructionOf(testingCityNames[0])
+	assert.Equal(5, d.Turn)
+
+	//an isolated city is not a destroyed one
+	game.cutOff(testingCityNames[3])
+	assert.Empty(game.CityMap[testingCityNames[3]].Links())
+	assert.False(game.IsDestroyed(testingCityNames[3]))
+	_, ok = game.DestructionOf(testingCityNames[3])
+	assert.False(ok)
+
+	game.DestroyCity(testingCityNames[3])
+	d, _ = game.DestructionOf(testingCityNames[3])
+	assert.Equal(Destruction{Turn: 6, Aliens: []string{}}, d)
+	assert.Equal([]string{testingCityNames[0], testingCityNames[3]}, game.DestroyedCities())
+}
+
+func TestNoEnteringDestroyedCity(t *testing.T) {
+	assert := assert.New(t)
+
+	//a road left to a destroyed city by hand is not taken
+	game := generateGame()
+	game.DestroyCity(testingCityNames[1])
+	game.CityMap[testingCityNames[0]].East = game.CityMap[testingCityNames[1]]
+	game.MakeMove(map[string]int{testingAlien: east})
+	assert.Equal(testingCityNames[0], game.AlienLocations[testingAlien])
+
+	//aliens on the road go back when their destination is destroyed
+	game = generateGame()
+	game.CityMap[testingCityNames[0]].SetCost(east, 2)
+	game.MakeMove(map[string]int{testingAlien: east})
+	assert.Equal(1, len(game.Transits))
+	game.DestroyCity(testingCityNames[1])
+	game.MakeMove(map[string]int{})
+	assert.Empty(game.Transits)
+	assert.Equal(testingCityNames[0], game.AlienLocations[testingAlien])
+	assert.Equal([]string{testingAlien}, game.livingAliensIn(testingCityNames[0]))
+}
+
+func TestDestroyKillsAliensOnRoad(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	game.CityMap[testingCityNames[0]].SetCost(east, 3)
+	game.MakeMove(map[string]int{testingAlien: east})
+	assert.Equal(1, len(game.Transits))
+
+	//the meteor falls on the first city left standing, the one the alien left
+	game.strikeMeteor(fakeZeroGenerator)
+	assert.True(game.IsDestroyed(testingCityNames[0]))
+	assert.Empty(game.AlienLocations)
+	assert.Empty(game.Transits)
+	assert.Equal([]Event{
+		{Kind: CityDestroyed, Cities: []string{testingCityNames[0]}, Aliens: []string{}, Outcome: "meteor"},
+		{Kind: DiedOnRoad, Cities: []string{testingCityNames[0], testingCityNames[1]},
+			Aliens: []string{testingAlien}, Outcome: "road cut off"},
+	}, game.Events)
+
+	//aliens on the roads into a destroyed city are left alone
+	game = generateGame()
+	game.CityMap[testingCityNames[0]].SetCost(east, 3)
+	game.MakeMove(map[string]int{testingAlien: east})
+	game.DestroyCity(testingCityNames[1])
+	assert.Equal(1, len(game.Transits))
+}
+
+func TestNoWayBackToDestroyedCity(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, order := range [][]int{{0, 1}, {1, 0}} {
+		game := generateGame()
+		game.CityMap[testingCityNames[0]].SetCost(east, 3)
+		game.MakeMove(map[string]int{testingAlien: east})
+		for _, i := range order {
+			game.DestroyCity(testingCityNames[i])
+		}
+		game.MakeMove(map[string]int{})
+		game.MakeMove(map[string]int{})
+		assert.Empty(game.AlienLocations, order)
+		assert.Empty(game.livingAliensIn(testingCityNames[0]), order)
+		assert.Empty(game.livingAliensIn(testingCityNames[1]), order)
+		assert.Equal(1, len(game.EventsOf(DiedOnRoad)), order)
+	}
+
+	//the same happens to the cities destroyed by aliens fighting in them
+	game := generateGame()
+	game.CityMap[testingCityNames[0]].SetCost(east, 3)
+	game.MakeMove(map[string]int{testingAlien: east})
+	for i, alien := range generators.AlienNames[1:5] {
+		withAlien(alien, testingCityNames[1-i/2])(game)
+		game.CheckAndDestroy()
+	}
+	assert.True(game.IsDestroyed(testingCityNames[0]))
+	assert.True(game.IsDestroyed(testingCityNames[1]))
+	assert.Empty(game.AlienLocations)
+	assert.Empty(game.Transits)
+	assert.Equal([]Event{{
+		Kind:    DiedOnRoad,
+		Cities:  []string{testingCityNames[0], testingCityNames[1]},
+		Aliens:  []string{testingAlien},
+		Outcome: "road cut off",
+	}}, game.EventsOf(DiedOnRoad))
+}
@@ -0,0 +1,169 @@
+package games
+
+import (
+	"github.com/hatricker/alieninvasion/generators"
+)
+
+//Ending tells why a game ended
+type Ending int
+
+const (
+	//MovesDone ends the game once all its moves are played
+	MovesDone Ending = iota
+	//NoAliens ends the game when no alien is left and none is to come
+	NoAliens
+	//Stranded ends the game when no alien has the energy to move again
+	Stranded
+	//Trapped ends the game when every alien is in a city without roads out
+	Trapped
+	//Apart ends the game when no two aliens can ever meet, as they are in
+	//parts of the map without roads between them
+	Apart
+	//Stalemate ends the game when nothing happened for a number of moves,
+	//see WithStalemate
+	Stalemate
+)
+
+//endingNames holds the names of the endings used when printing them
+var endingNames = map[Ending]string{
+	MovesDone: "all moves done",
+	NoAliens:  "no aliens left",
+	Stranded:  "all aliens stranded without energy",
+	Trapped:   "all aliens trapped",
+	Apart:     "no two aliens can meet",
+	Stalemate: "stalemate",
+}
+
+func (e Ending) String() string {
+	return endingNames[e]
+}
+
+//WithStalemate ends the game once the given number of moves went by without
+//any event, see Event. The game does not end so when it is not positive
+func WithStalemate(moves int) Option {
+	return func(g *Game) {
+		g.stalemate = moves
+	}
+}
+
+//ending tells whether the game ends before the current move, and why
+func (g *Game) ending() (Ending, bool) {
+	switch {
+	case len(g.AlienLocations) == 0 && !g.wavesAhead(g.turn):
+		return NoAliens, true
+	case g.stranded():
+		return Stranded, true
+	case g.trapped():
+		return Trapped, true
+	case g.apart():
+		return Apart, true
+	case g.stalled():
+		return Stalemate, true
+	}
+	return MovesDone, false
+}
+
+//settled tells whether nothing but the aliens themselves can change the game
+//any more: there are no humans, reinforcements, offspring, map events, fire
+//or rebuilt cities
+func (g *Game) settled() bool {
+	return len(g.HumanLocations) == 0 && !g.wavesAhead(g.turn) && !g.reproduction.enabled() &&
+		len(g.mapEvents) == 0 && len(g.fires) == 0 && !g.rebuilding.enabled()
+}
+
+//trapped tells whether every alien is in a city without roads or wormholes
+//out, and nothing else can change that
+func (g *Game) trapped() bool {
+	if len(g.AlienLocations) == 0 || len(g.Transits) > 0 || !g.settled() {
+		return false
+	}
+	for _, city := range g.AlienLocations {
+		if len(roadsOf(g.CityMap[city])) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+//apart tells whether no two aliens which would fight are in the same
+//connected part of the map, so they can never meet, and nothing else, like
+//aliens dying out of energy, can change the game. Aliens on the road count
+//from the city they arrive in, see destination
+func (g *Game) apart() bool {
+	if len(g.AlienLocations) == 0 || !g.settled() || (g.energy.enabled() && g.energy.Die) {
+		return false
+	}
+	components := g.cityComponents()
+	//the first alien found in each component, any other alien there must be
+	//at peace with it
+	found := make(map[int]string)
+	for _, alien := range g.alienNames() {
+		city := g.AlienLocations[alien]
+		if transit, ok := g.Transits[alien]; ok {
+			city = g.destination(transit)
+		}
+		component := components[city]
+		first, ok := found[component]
+		if !ok {
+			found[component] = alien
+			continue
+		}
+		if !g.peaceful([]string{first, alien}) {
+			return false
+		}
+	}
+	return true
+}
+
+//cityComponents returns the connected component of each city, following
+//roads and wormholes both ways. It is only worked out again after the map
+//changed, see mapChanged
+func (g *Game) cityComponents() map[string]int {
+	if g.components != nil {
+		return g.components
+	}
+	linked := make(map[string][]string, len(g.CityMap))
+	for city, node := range g.CityMap {
+		for _, direction := range node.Links() {
+			neighbor := node.Neighbor(direction).Name
+			linked[city] = append(linked[city], neighbor)
+			linked[neighbor] = append(linked[neighbor], city)
+		}
+	}
+	g.components = make(map[string]int, len(g.CityMap))
+	for i, city := range generators.SortedCityNames(g.CityMap) {
+		if _, ok := g.components[city]; ok {
+			continue
+		}
+		g.components[city] = i
+		queue := []string{city}
+		for len(queue) > 0 {
+			for _, neighbor := range linked[queue[0]] {
+				if _, ok := g.components[neighbor]; !ok {
+					g.components[neighbor] = i
+					queue = append(queue, neighbor)
+				}
+			}
+			queue = queue[1:]
+		}
+	}
+	return g.components
+}
+
+//mapChanged drops what was worked out from the roads of the map
+func (g *Game) mapChanged() {
+	g.components = nil
+}
+
+//stalled tells whether the game went without any event for the number of
+//moves set by WithStalemate
+func (g *Game) stalled() bool {
+	if g.stalemate <= 0 {
+		return false
+	}
+	quietSince := 0
+	if n := len(g.Events); n > 0 {
+		quietSince = g.Events[n-1].Turn + 1
+	}
+	return g.turn-quietSince >= g.stalemate
+}
@@ -0,0 +1,85 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/hatricker/alieninvasion/generators"
+	"github.com/stretchr/testify/assert"
+)
+
+//secondAlien puts a second alien in the last testing city
+var secondAlien = withAlien(generators.AlienNames[1], testingCityNames[3])
+
+func TestStartGameEndings(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		game   *Game
+		ending Ending
+		moves  int
+	}{
+		{NewGame(nil, generateCityMap(), fakeZeroGenerator), NoAliens, 0},
+		{generateGame(secondAlien, withSplitMap()), Apart, 0},
+		{generateGame(secondAlien, WithFaction(testingAlien, "red"), WithFaction(generators.AlienNames[1], "red")), Apart, 0},
+		{generateGame(secondAlien, WithCollisionRule(&MutualDestructionRule{Threshold: 3})), MovesDone, 5},
+		{generateGame(secondAlien, WithCollisionRule(&MutualDestructionRule{Threshold: 3}), WithStalemate(3)), Stalemate, 3},
+	}
+	for i, tt := range tests {
+		ending, moves := tt.game.StartGame(5)
+		assert.Equal(tt.ending, ending, i)
+		assert.Equal(tt.moves, moves, i)
+	}
+}
+
+func TestTrapped(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame(secondAlien)
+	game.cutOff(testingCityNames[0])
+	assert.False(game.trapped())
+	game.cutOff(testingCityNames[3])
+	assert.True(game.trapped())
+	ending, moves := game.StartGame(5)
+	assert.Equal(Trapped, ending)
+	assert.Equal(0, moves)
+
+	//humans or aliens on the road may still change the game
+	WithHumans("Ripley")(game)
+	game.HumanLocations = map[string]string{"Ripley": testingCityNames[1]}
+	assert.False(game.trapped())
+	game.HumanLocations = nil
+	game.Transits = map[string]*Transit{testingAlien: {From: testingCityNames[0], To: testingCityNames[1], Remaining: 1}}
+	assert.False(game.trapped())
+}
+
+func TestApart(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.False(generateGame(secondAlien).apart())
+	assert.True(generateGame(secondAlien, withSplitMap()).apart())
+
+	//aliens dying out of energy change the game even when they cannot meet
+	assert.False(generateGame(secondAlien, withSplitMap(), WithEnergy(Energy{Capacity: 5, Die: true})).apart())
+
+	//an alien on the road counts from the city it is heading to, or from the
+	//city it left when the other one is destroyed
+	game := generateGame(secondAlien, withSplitMap())
+	game.Transits = map[string]*Transit{testingAlien: {From: testingCityNames[2], To: testingCityNames[0], Remaining: 1}}
+	assert.True(game.apart())
+	game.DestroyCity(testingCityNames[0])
+	assert.False(game.apart())
+
+	//the components are worked out again once the map changes
+	game = generateGame(secondAlien)
+	assert.False(game.apart())
+	game.cutOff(testingCityNames[1])
+	game.cutOff(testingCityNames[2])
+	assert.True(game.apart())
+}
+
+func TestEndingString(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("all moves done", MovesDone.String())
+	assert.Equal("no two aliens can meet", Apart.String())
+}
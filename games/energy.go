@@ -0,0 +1,166 @@
+package games
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+)
+
+//Energy limits how far aliens go. Each alien starts with Capacity energy
+//and taking a road costs MoveCost (1 when not set) times the cost of the
+//road. An alien without enough energy for any road is stranded, and with
+//Die set an alien dies once its energy runs out. Aliens in a city left
+//standing regain Recharge energy each move, up to Capacity
+//Energy is not used when Capacity is not set
+type Energy struct {
+	Capacity int  `json:"capacity"`
+	MoveCost int  `json:"cost"`
+	Recharge int  `json:"recharge"`
+	Die      bool `json:"die"`
+}
+
+//validate checks the energy settings can be used
+func (e *Energy) validate() error {
+	if e.Capacity < 0 || e.MoveCost < 0 || e.Recharge < 0 {
+		return fmt.Errorf("energy settings cannot be negative")
+	}
+	if !e.enabled() && (e.MoveCost > 0 || e.Recharge > 0 || e.Die) {
+		return fmt.Errorf("energy settings need a capacity")
+	}
+	return nil
+}
+
+//enabled tells whether aliens use energy at all
+func (e *Energy) enabled() bool {
+	return e.Capacity > 0
+}
+
+//cost returns the energy needed to take a road of the given cost
+func (e *Energy) cost(roadCost int) int {
+	if e.MoveCost == 0 {
+		return roadCost
+	}
+	return e.MoveCost * roadCost
+}
+
+//WithEnergy makes aliens spend energy moving, see Energy
+func WithEnergy(e Energy) Option {
+	return func(g *Game) {
+		g.energy = e
+	}
+}
+
+//fillEnergy gives the aliens without energy a full charge
+func (g *Game) fillEnergy(aliens []string) {
+	if !g.energy.enabled() {
+		return
+	}
+	for _, alien := range aliens {
+		if a := g.Aliens[alien]; a != nil && a.Energy == 0 {
+			a.Energy = g.energy.Capacity
+		}
+	}
+}
+
+//canAfford tells whether the alien has the energy to take a road
+//of the given cost
+func (g *Game) canAfford(alien string, roadCost int) bool {
+	if !g.energy.enabled() {
+		return true
+	}
+	a := g.Aliens[alien]
+	return a == nil || a.Energy >= g.energy.cost(roadCost)
+}
+
+//spend takes the energy for a road of the given cost from the alien
+func (g *Game) spend(alien string, roadCost int) {
+	if a := g.Aliens[alien]; a != nil && g.energy.enabled() {
+		a.Energy -= g.energy.cost(roadCost)
+	}
+}
+
+//exhaust kills the aliens which ran out of energy, when aliens die of it
+func (g *Game) exhaust() {
+	if !g.energy.enabled() || !g.energy.Die {
+		return
+	}
+	for _, alien := range g.alienNames() {
+		if a := g.Aliens[alien]; a == nil || a.Energy > 0 {
+			continue
+		}
+		city := g.AlienLocations[alien]
+		if transit, ok := g.Transits[alien]; ok {
+			city = transit.From
+			delete(g.Transits, alien)
+		}
+		delete(g.AlienLocations, alien)
+		log.Printf("!!!!!!Alien %s ran out of energy near %s !!!!!!", alien, city)
+		g.record(OutOfEnergy, []string{city}, []string{alien}, "died")
+	}
+}
+
+//recharge gives energy back to the aliens in cities left standing
+func (g *Game) recharge() {
+	if !g.energy.enabled() || g.energy.Recharge == 0 {
+		return
+	}
+	for alien, city := range g.AlienLocations {
+		a := g.Aliens[alien]
+		if _, ok := g.Transits[alien]; ok || a == nil || g.IsDestroyed(city) {
+			continue
+		}
+		if a.Energy += g.energy.Recharge; a.Energy > g.energy.Capacity {
+			a.Energy = g.energy.Capacity
+		}
+	}
+}
+
+//stranded tells whether no alien can ever move again: none of them is on
+//the road, has the energy for a road out of its city or can recharge, and
+//nothing else can change that, see settled
+func (g *Game) stranded() bool {
+	if !g.energy.enabled() || len(g.AlienLocations) == 0 || !g.settled() {
+		return false
+	}
+	for alien, city := range g.AlienLocations {
+		if _, ok := g.Transits[alien]; ok {
+			return false
+		}
+		if g.energy.Recharge > 0 && !g.IsDestroyed(city) {
+			return false
+		}
+		node := g.CityMap[city]
+		for _, direction := range roadsOf(node) {
+			if g.canAfford(alien, node.Cost(direction)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+//ParseEnergy parses comma separated energy settings made of
+//"capacity=<energy>", "cost=<energy per move>", "recharge=<energy per move>"
+//and "die", e.g. "capacity=20,recharge=1"
+func ParseEnergy(spec string) (Energy, error) {
+	var e Energy
+	err := parseSettings(spec, "energy", []string{"die"}, func(key, value string) (err error) {
+		switch key {
+		case "die":
+			e.Die = true
+		case "capacity":
+			e.Capacity, err = strconv.Atoi(value)
+		case "cost":
+			e.MoveCost, err = strconv.Atoi(value)
+		case "recharge":
+			e.Recharge, err = strconv.Atoi(value)
+		default:
+			err = errUnknownSetting
+		}
+		return err
+	})
+	if err != nil {
+		return e, err
+	}
+	return e, e.validate()
+}
@@ -0,0 +1,111 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/hatricker/alieninvasion/generators"
+	"github.com/stretchr/testify/assert"
+)
+
+//generateEnergyGame returns the testing game with the alien charged up
+func generateEnergyGame(e Energy) *Game {
+	game := generateGame()
+	WithEnergy(e)(game)
+	game.assignAttributes([]string{testingAlien})
+	return game
+}
+
+func TestEnergySpentAndStranded(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateEnergyGame(Energy{Capacity: 2})
+	assert.Equal(2, game.Aliens[testingAlien].Energy)
+
+	game.MakeMove(map[string]int{testingAlien: east})
+	game.MakeMove(map[string]int{testingAlien: west})
+	assert.Equal(testingCityNames[0], game.AlienLocations[testingAlien])
+	assert.Equal(0, game.Aliens[testingAlien].Energy)
+	assert.True(game.stranded())
+
+	game.MakeMove(map[string]int{testingAlien: east})
+	assert.Equal(testingCityNames[0], game.AlienLocations[testingAlien])
+
+	game = generateEnergyGame(Energy{Capacity: 5, MoveCost: 2})
+	game.CityMap[testingCityNames[0]].SetCost(east, 2)
+	game.MakeMove(map[string]int{testingAlien: east})
+	assert.Equal(1, game.Aliens[testingAlien].Energy)
+	assert.Equal(1, len(game.Transits))
+}
+
+func TestExhaustKills(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, die := range []bool{false, true} {
+		game := generateEnergyGame(Energy{Capacity: 1, Die: die})
+		game.MakeMove(map[string]int{testingAlien: east})
+		game.exhaust()
+		if !die {
+			assert.Equal(1, len(game.AlienLocations))
+			continue
+		}
+		assert.Empty(game.AlienLocations)
+		assert.Equal([]Event{{
+			Kind:    OutOfEnergy,
+			Cities:  []string{testingCityNames[1]},
+			Aliens:  []string{testingAlien},
+			Outcome: "died",
+		}}, game.Events)
+	}
+}
+
+func TestRecharge(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateEnergyGame(Energy{Capacity: 2, Recharge: 1})
+	game.Aliens[testingAlien].Energy = 0
+	assert.False(game.stranded())
+
+	game.recharge()
+	assert.Equal(1, game.Aliens[testingAlien].Energy)
+	game.recharge()
+	game.recharge()
+	assert.Equal(2, game.Aliens[testingAlien].Energy)
+
+	game.Aliens[testingAlien].Energy = 0
+	game.DestroyCity(testingCityNames[0])
+	game.recharge()
+	assert.Equal(0, game.Aliens[testingAlien].Energy)
+	assert.True(game.stranded())
+}
+
+func TestStartGameStopsWhenStranded(t *testing.T) {
+	assert := assert.New(t)
+
+	//a second alien, which could meet the first one before both ran out of energy
+	another := generators.AlienNames[1]
+	game := NewGame([]string{testingAlien, another}, generateCityMap(), fakeZeroGenerator,
+		WithEnergy(Energy{Capacity: 1}), WithPolicy(&RoadsPolicy{}))
+	ending, moves := game.StartGame(100)
+	assert.Equal(Stranded, ending)
+	assert.Equal(1, moves)
+	assert.Equal(1, game.turn)
+	assert.Equal(testingCityNames[1], game.AlienLocations[testingAlien])
+	assert.Equal(testingCityNames[3], game.AlienLocations[another])
+}
+
+func TestParseEnergy(t *testing.T) {
+	assert := assert.New(t)
+
+	e, err := ParseEnergy("capacity=20, cost=2,recharge=1,die")
+	assert.Nil(err)
+	assert.Equal(Energy{Capacity: 20, MoveCost: 2, Recharge: 1, Die: true}, e)
+
+	e, err = ParseEnergy("")
+	assert.Nil(err)
+	assert.False(e.enabled())
+
+	for _, spec := range []string{"capacity", "capacity=x", "capacity=-1", "fuel=3", "recharge=1", "die", "capacity=5,die=1"} {
+		_, err := ParseEnergy(spec)
+		assert.NotNil(err, spec)
+	}
+}
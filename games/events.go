@@ -0,0 +1,97 @@
+package games
+
+import (
+	"fmt"
+	"strings"
+)
+
+//EventKind tells what kind of thing happened in an Event
+type EventKind int
+
+const (
+	//CityDestroyed is recorded when a city is destroyed, see Destruction
+	CityDestroyed EventKind = iota
+	//RoadCrossing is recorded when aliens meet head-on on a road
+	RoadCrossing
+	//CityFight is recorded when aliens die fighting in a city which stands
+	CityFight
+	//CityDefended is recorded when the defense of a city repels an attack
+	CityDefended
+	//HumanDefense is recorded when humans fight aliens in a city
+	HumanDefense
+	//Reinforcement is recorded when a wave of new aliens lands
+	Reinforcement
+	//Offspring is recorded when an alien spawns an offspring into the next city
+	Offspring
+	//OutOfEnergy is recorded when an alien dies running out of energy
+	OutOfEnergy
+	//RoadCollapsed is recorded when a map event cuts a road
+	RoadCollapsed
+	//RoadOpened is recorded when a map event builds a new road
+	RoadOpened
+	//CityRebuilt is recorded when a destroyed city is rebuilt
+	CityRebuilt
+	//DiedOnRoad is recorded when an alien dies on a road cut off by the
+	//destruction of a city
+	DiedOnRoad
+)
+
+//eventNames holds the names of the event kinds used when printing events
+var eventNames = map[EventKind]string{
+	CityDestroyed: "city destroyed",
+	RoadCrossing:  "road crossing",
+	CityFight:     "fight",
+	CityDefended:  "attack repelled",
+	HumanDefense:  "human defense",
+	Reinforcement: "reinforcements",
+	Offspring:     "offspring",
+	OutOfEnergy:   "out of energy",
+	RoadCollapsed: "road collapse",
+	RoadOpened:    "new road",
+	CityRebuilt:   "city rebuilt",
+	DiedOnRoad:    "died on the road",
+}
+
+//Event records something which happened during the game
+//Cities holds the city the event happened in, or both ends of the road
+//Aliens holds the aliens involved and Outcome describes the result
+type Event struct {
+	Turn    int
+	Kind    EventKind
+	Cities  []string
+	Aliens  []string
+	Outcome string
+}
+
+func (e Event) String() string {
+	s := fmt.Sprintf("move #%d, %s at %s", e.Turn, eventNames[e.Kind], strings.Join(e.Cities, "-"))
+	if len(e.Aliens) > 0 {
+		s += " by " + strings.Join(e.Aliens, " ")
+	}
+	if e.Outcome != "" {
+		s += ", " + e.Outcome
+	}
+	return s
+}
+
+//record adds an event which happened in the current move to the game's log
+func (g *Game) record(kind EventKind, cities, aliens []string, outcome string) {
+	g.Events = append(g.Events, Event{
+		Turn:    g.turn,
+		Kind:    kind,
+		Cities:  cities,
+		Aliens:  append([]string{}, aliens...),
+		Outcome: outcome,
+	})
+}
+
+//EventsOf returns the events of one kind in the order they happened
+func (g *Game) EventsOf(kind EventKind) []Event {
+	var events []Event
+	for _, e := range g.Events {
+		if e.Kind == kind {
+			events = append(events, e)
+		}
+	}
+	return events
+}
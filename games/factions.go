@@ -0,0 +1,68 @@
+package games
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//WithFaction puts an alien in a faction. Aliens of the same faction share
+//cities and roads peacefully, aliens without a faction fight everyone
+func WithFaction(alien, faction string) Option {
+	return func(g *Game) {
+		if g.Factions == nil {
+			g.Factions = make(map[string]string)
+		}
+		g.Factions[alien] = faction
+	}
+}
+
+//peaceful tells whether all the aliens belong to the same faction,
+//in which case they do not fight each other
+func (g *Game) peaceful(aliens []string) bool {
+	if len(aliens) == 0 {
+		return true
+	}
+	faction := g.Factions[aliens[0]]
+	if faction == "" {
+		return false
+	}
+	for _, alien := range aliens[1:] {
+		if g.Factions[alien] != faction {
+			return false
+		}
+	}
+	return true
+}
+
+//SurvivorsByFaction returns the living aliens sorted by name, keyed by
+//their faction. Aliens without a faction are kept under ""
+func (g *Game) SurvivorsByFaction() map[string][]string {
+	survivors := make(map[string][]string)
+	for alien := range g.AlienLocations {
+		faction := g.Factions[alien]
+		survivors[faction] = append(survivors[faction], alien)
+	}
+	for _, aliens := range survivors {
+		sort.Strings(aliens)
+	}
+	return survivors
+}
+
+//ParseFactions parses a comma separated list of <alien>=<faction>
+//pairs, e.g. "Zidane=red,Salah=red,Degir=blue"
+func ParseFactions(spec string) (map[string]string, error) {
+	factions := make(map[string]string)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		i := strings.LastIndex(pair, "=")
+		faction := strings.TrimSpace(pair[i+1:])
+		if i <= 0 || faction == "" {
+			return nil, fmt.Errorf("invalid alien faction %q, expecting <alien>=<faction>", pair)
+		}
+		factions[strings.TrimSpace(pair[:i])] = faction
+	}
+	return factions, nil
+}
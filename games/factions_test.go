@@ -0,0 +1,78 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/hatricker/alieninvasion/generators"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFactionsInCity(t *testing.T) {
+	assert := assert.New(t)
+
+	aliens := generators.AlienNames[:3]
+	tests := []struct {
+		factions  []string
+		survivors int
+	}{
+		{[]string{"", "", ""}, 0},
+		{[]string{"red", "red", "red"}, 3},
+		{[]string{"red", "red", "blue"}, 0},
+		{[]string{"red", "red", ""}, 0},
+	}
+
+	for _, tt := range tests {
+		var opts []Option
+		for i, faction := range tt.factions {
+			if faction != "" {
+				opts = append(opts, WithFaction(aliens[i], faction))
+			}
+		}
+		game, _ := generateCrowdedGame(len(aliens), opts...)
+		game.CheckAndDestroy()
+
+		assert.Equal(tt.survivors, len(game.AlienLocations), tt.factions)
+		assert.Equal(tt.survivors == 0, game.IsDestroyed(testingCityNames[0]), tt.factions)
+	}
+}
+
+func TestFactionsOnRoad(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, mode := range []MoveMode{MoveSequential, MoveSimultaneous} {
+		aliens := generators.AlienNames[:2]
+		game, _ := generateSwapGame(WithMoveMode(mode), WithCrossingRule(CrossFight),
+			WithFaction(aliens[0], "red"), WithFaction(aliens[1], "red"))
+		game.MakeMove(map[string]int{aliens[0]: east, aliens[1]: west})
+		game.CheckAndDestroy()
+
+		assert.Equal(testingCityNames[1], game.AlienLocations[aliens[0]])
+		assert.Equal(testingCityNames[0], game.AlienLocations[aliens[1]])
+		assert.Empty(game.Events)
+	}
+}
+
+func TestSurvivorsByFaction(t *testing.T) {
+	assert := assert.New(t)
+
+	aliens := generators.AlienNames[:3]
+	game := NewGame(aliens, generateCityMap(), fakeZeroGenerator,
+		WithFaction(aliens[2], "red"), WithFaction(aliens[0], "red"))
+	assert.Equal(map[string][]string{
+		"red": {aliens[2], aliens[0]},
+		"":    {aliens[1]},
+	}, game.SurvivorsByFaction())
+}
+
+func TestParseFactions(t *testing.T) {
+	assert := assert.New(t)
+
+	factions, err := ParseFactions("Zidane=red, Salah=red,Degir=blue")
+	assert.Nil(err)
+	assert.Equal(map[string]string{"Zidane": "red", "Salah": "red", "Degir": "blue"}, factions)
+
+	for _, spec := range []string{"Zidane", "=red", "Zidane="} {
+		_, err := ParseFactions(spec)
+		assert.NotNil(err, spec)
+	}
+}
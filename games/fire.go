@@ -0,0 +1,134 @@
+package games
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+)
+
+//defaultBurn is the number of moves a destroyed city burns when Fire
+//does not set it
+const defaultBurn = 3
+
+//Fire makes the destruction of a city spread. A destroyed city burns for
+//Burn moves (3 when not set), and at each of them sets each city it had
+//a road or wormhole to on fire with the probability Chance (0-1). A city
+//set on fire is destroyed along with everyone in it, whatever its defense,
+//and starts burning in turn. Fire is not used when Chance is not set
+type Fire struct {
+	Chance float64 `json:"chance"`
+	Burn   int     `json:"burn"`
+}
+
+//validate checks the fire settings can be used
+func (f *Fire) validate() error {
+	if f.Chance < 0 || f.Chance > 1 {
+		return fmt.Errorf("fire chance must be within 0-1")
+	}
+	if f.Burn < 0 {
+		return fmt.Errorf("fire cannot burn for a negative number of moves")
+	}
+	if !f.enabled() && f.Burn > 0 {
+		return fmt.Errorf("fire settings need a chance")
+	}
+	return nil
+}
+
+//enabled tells whether destruction spreads at all
+func (f *Fire) enabled() bool {
+	return f.Chance > 0
+}
+
+//burning is a destroyed city on fire, neighbors are the cities it had links
+//with before it was cut off and left the number of moves it still burns
+type burning struct {
+	neighbors []string
+	left      int
+}
+
+//WithFire makes the destruction of cities spread to their neighbors, see Fire
+func WithFire(f Fire) Option {
+	return func(g *Game) {
+		g.fire = f
+	}
+}
+
+//ignite sets the city on fire, remembering the cities it has links with.
+//It runs before the links of the destroyed city are cut
+func (g *Game) ignite(city string) {
+	if !g.fire.enabled() {
+		return
+	}
+	node := g.CityMap[city]
+	seen := map[string]bool{}
+	for _, direction := range node.Links() {
+		seen[node.Neighbor(direction).Name] = true
+	}
+	for _, other := range g.CityMap {
+		for _, direction := range other.Links() {
+			if other.Neighbor(direction) == node {
+				seen[other.Name] = true
+			}
+		}
+	}
+	delete(seen, city)
+	var neighbors []string
+	for name := range seen {
+		neighbors = append(neighbors, name)
+	}
+	sort.Strings(neighbors)
+	burn := g.fire.Burn
+	if burn == 0 {
+		burn = defaultBurn
+	}
+	if g.fires == nil {
+		g.fires = map[string]*burning{}
+	}
+	g.fires[city] = &burning{neighbors: neighbors, left: burn}
+}
+
+//spreadFire lets each burning city set its neighbors on fire, then burns
+//it down by one move. Cities set on fire now only spread it from the next move
+func (g *Game) spreadFire() {
+	var cities []string
+	for city := range g.fires {
+		cities = append(cities, city)
+	}
+	sort.Strings(cities)
+	gen := g.stream("fire")
+	for _, city := range cities {
+		f := g.fires[city]
+		for _, neighbor := range f.neighbors {
+			if g.IsDestroyed(neighbor) || !chance(gen, g.fire.Chance) {
+				continue
+			}
+			log.Printf("!!!!!!City %s has been destroyed by fire spreading from %s !!!!!!", neighbor, city)
+			g.wipeOut(neighbor, "fire from "+city)
+		}
+		if f.left--; f.left <= 0 {
+			delete(g.fires, city)
+		}
+	}
+}
+
+//ParseFire parses comma separated fire settings made of "chance=<0-1>"
+//and "burn=<moves>", e.g. "chance=0.2,burn=3"
+func ParseFire(spec string) (Fire, error) {
+	var f Fire
+	err := parseSettings(spec, "fire", nil, func(key, value string) (err error) {
+		switch key {
+		case "chance":
+			f.Chance, err = strconv.ParseFloat(value, 64)
+		case "burn":
+			f.Burn, err = strconv.Atoi(value)
+		default:
+			err = errUnknownSetting
+		}
+		return err
+	})
+	if err != nil {
+		return f, err
+	}
+	return f, f.validate()
+}
@@ -0,0 +1,82 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFireSpreads(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	WithFire(Fire{Chance: 1, Burn: 1})(game)
+	game.DestroyCity(testingCityNames[3])
+	assert.ElementsMatch([]string{testingCityNames[1], testingCityNames[2]}, game.fires[testingCityNames[3]].neighbors)
+
+	game.spreadFire()
+	assert.True(game.IsDestroyed(testingCityNames[1]))
+	assert.True(game.IsDestroyed(testingCityNames[2]))
+	assert.NotContains(game.fires, testingCityNames[3])
+	assert.Equal("fire from "+testingCityNames[3], game.Events[0].Outcome)
+
+	//the cities set on fire spread it at the next move, killing the alien
+	assert.False(game.IsDestroyed(testingCityNames[0]))
+	game.spreadFire()
+	assert.True(game.IsDestroyed(testingCityNames[0]))
+	assert.Empty(game.AlienLocations)
+	assert.Equal([]string{testingAlien}, game.Events[2].Aliens)
+	assert.Equal(3, len(game.EventsOf(CityDestroyed)))
+	//the city is set on fire by the first of its burning neighbors
+	d, ok := game.DestructionOf(testingCityNames[0])
+	assert.True(ok)
+	assert.Equal(Destruction{Aliens: []string{}, Cause: "fire from " + testingCityNames[2]}, d)
+
+	game.spreadFire()
+	assert.Empty(game.fires)
+}
+
+func TestFireBurnsOut(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	WithFire(Fire{Chance: 0.5})(game)
+	game.DestroyCity(testingCityNames[3])
+
+	//the fire never spreads with a generator always giving the largest number
+	game.randGen = fakeMaxGenerator
+	for i := 0; i < defaultBurn; i++ {
+		assert.Contains(game.fires, testingCityNames[3])
+		game.spreadFire()
+	}
+	assert.Empty(game.fires)
+	assert.Equal([]string{testingCityNames[3]}, game.DestroyedCities())
+
+	//cities do not catch fire without it
+	game = generateGame()
+	game.DestroyCity(testingCityNames[3])
+	assert.Empty(game.fires)
+}
+
+func TestParseFire(t *testing.T) {
+	assert := assert.New(t)
+
+	valid := []struct {
+		spec string
+		fire Fire
+	}{
+		{"", Fire{}},
+		{"chance=0.2", Fire{Chance: 0.2}},
+		{"chance=1, burn=5", Fire{Chance: 1, Burn: 5}},
+	}
+	for _, tt := range valid {
+		f, err := ParseFire(tt.spec)
+		assert.Nil(err, tt.spec)
+		assert.Equal(tt.fire, f, tt.spec)
+	}
+
+	for _, spec := range []string{"chance", "chance=x", "chance=1.5", "burn=2", "chance=0.1,burn=-1", "heat=3"} {
+		_, err := ParseFire(spec)
+		assert.NotNil(err, spec)
+	}
+}
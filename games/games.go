@@ -1,7 +1,9 @@
 package games
 
 import (
+	"fmt"
 	"log"
+	"sort"
 	"strings"
 
 	"github.com/hatricker/alieninvasion/generators"
@@ -10,11 +12,107 @@ import (
 //Game keeps game state
 //AlienLocations keeps a map with key as alien and value as the city where alien stays
 //CityMap holds the current cities, paths among them(neighbors), and alien(s) in each city
-//randGen holds a random number generator object
+//OriginalMap keeps a copy of the map as it was when the game started, which
+//rebuilt cities take their roads back from
+//Aliens holds the combat attributes of each alien, see Alien
+//Factions keeps the faction of each alien, aliens of the same faction do not fight
+//HumanLocations keeps the city of each living human defender, see WithHumans
+//Transits keeps the aliens travelling along a road which costs more than one move,
+//such aliens stay in AlienLocations with the city they left
+//Events keeps what happened during the game, such as destroyed cities
+//Destroyed keeps how each destroyed city was destroyed, see Destruction
+//randGen holds a random number generator object. When it is splittable, each
+//subsystem and each alien draws from its own stream kept in streams, so the
+//numbers an alien gets do not depend on the other aliens
+//policy and alienPolicies decide where the aliens go, see MovePolicy
+//collision decides what happens when aliens meet, see CollisionRule
+//defenseDecay is the number of moves after which city defenses drop by one
+//humans are the human defenders put on the map, humanPolicy moves them
+//waves are the reinforcements landing during the game, see Wave
+//reproduction decides when aliens breed, births keeps the move each alien
+//was born or last bred at
+//energy limits how far the aliens go, see Energy
+//mapEvents change the map during the game, see MapEvent
+//fire makes destruction spread, fires keeps the cities burning, see Fire
+//rebuilding brings destroyed cities back, idle keeps the number of moves
+//each destroyed city has been without aliens, see Rebuild
+//stalemate is the number of moves without events which ends the game
+//components caches the connected component of each city, see cityComponents
+//order decides in which order the aliens move and fight, spawnOrder keeps
+//the order the aliens were put on the map in
 type Game struct {
 	AlienLocations map[string]string
 	CityMap        map[string]*generators.CityNode
+	OriginalMap    map[string]*generators.CityNode
+	Aliens         map[string]*Alien
+	Factions       map[string]string
+	HumanLocations map[string]string
+	Transits       map[string]*Transit
+	Events         []Event
+	Destroyed      map[string]*Destruction
+	turn           int
 	randGen        generators.NumGen
+	streams        map[string]generators.NumGen
+	order          Ordering
+	spawnOrder     []string
+	moveMode       MoveMode
+	crossing       CrossingRule
+	policy         MovePolicy
+	alienPolicies  map[string]MovePolicy
+	collision      CollisionRule
+	defenseDecay   int
+	humans         []string
+	humanPolicy    MovePolicy
+	waves          []Wave
+	reproduction   Reproduction
+	births         map[string]int
+	energy         Energy
+	mapEvents      []MapEvent
+	fire           Fire
+	fires          map[string]*burning
+	rebuilding     Rebuild
+	idle           map[string]int
+	stalemate      int
+	components     map[string]int
+}
+
+//Option changes the default settings of a game created by NewGame
+type Option func(*Game)
+
+//Ordering defines the order the game goes through the aliens in each move.
+//Either order is stable, so the same generator always produces the same game
+type Ordering int
+
+const (
+	//OrderByName goes through the aliens sorted by name. It is the default
+	OrderByName Ordering = iota
+	//OrderBySpawn goes through the aliens in the order they were put on the map
+	OrderBySpawn
+)
+
+//ParseOrdering converts the name of an ordering, "name" or "spawn", to its value
+func ParseOrdering(name string) (Ordering, error) {
+	switch name {
+	case "name":
+		return OrderByName, nil
+	case "spawn":
+		return OrderBySpawn, nil
+	}
+	return OrderByName, fmt.Errorf("unknown ordering %q", name)
+}
+
+//WithOrdering sets the order the game goes through the aliens
+func WithOrdering(order Ordering) Option {
+	return func(g *Game) {
+		g.order = order
+	}
+}
+
+//Transit describes an alien on its way from one city to another
+//Remaining is the number of moves left before the alien arrives
+type Transit struct {
+	From, To  string
+	Remaining int
 }
 
 //spreadAliensOntoMap spreads the aliens randomly on the map
@@ -25,10 +123,8 @@ func spreadAliensOntoMap(aliens []string,
 		log.Panic("aliens size must not larger than number of cities")
 	}
 	alienLocations := map[string]string{}
-	cityNames := make([]string, 0, len(cityMap))
-	for cn := range cityMap {
-		cityNames = append(cityNames, cn)
-	}
+	//sorted, so the same generator always places the aliens the same way
+	cityNames := generators.SortedCityNames(cityMap)
 
 	for _, alien := range aliens {
 		num := gen.GenerateNum(len(cityNames))
@@ -43,83 +139,262 @@ func spreadAliensOntoMap(aliens []string,
 }
 
 //NewGame initializes game state
-func NewGame(aliens []string, cityMap map[string]*generators.CityNode, gen generators.NumGen) *Game {
-	game := &Game{CityMap: cityMap, randGen: gen}
+func NewGame(aliens []string, cityMap map[string]*generators.CityNode, gen generators.NumGen, opts ...Option) *Game {
+	game := &Game{CityMap: cityMap, OriginalMap: generators.CloneCityMap(cityMap), Transits: map[string]*Transit{}, randGen: gen}
+	for _, opt := range opts {
+		opt(game)
+	}
 
-	game.AlienLocations = spreadAliensOntoMap(aliens, cityMap, gen)
+	game.AlienLocations = spreadAliensOntoMap(aliens, cityMap, game.stream("placement"))
+	game.assignAttributes(aliens)
+	game.placeHumans()
+	game.spawnOrder = append(game.spawnOrder, aliens...)
 	return game
 }
 
-//StartGame starts the game
-func (g *Game) StartGame(loop int) {
+//StartGame plays at most loop moves, and returns why the game ended along
+//with the number of moves played. The game ends early when it cannot
+//change any more, see Ending
+func (g *Game) StartGame(loop int) (Ending, int) {
 	for i := 0; i < loop; i++ {
-		if len(g.AlienLocations) == 0 {
-			//no aliens on the map, stop
-			break
+		g.turn = i
+		if ending, ok := g.ending(); ok {
+			log.Printf("%v at move #%d, stop", ending, i)
+			return ending, i
 		}
-		moves := g.GenMoves()
-		log.Printf("Move #%d: %v", i, moves)
-		g.MakeMove(moves)
+		g.changeMap()
+		g.landWaves()
+		g.moveAliens()
+		g.exhaust()
+		g.moveHumans()
 		g.CheckAndDestroy()
+		g.spreadFire()
+		g.recharge()
+		g.breed()
+		g.decayDefenses()
+		g.rebuild()
 	}
+	return MovesDone, loop
+}
+
+//stream returns the random number stream for name. Generators which cannot
+//be split give the same shared generator for every name
+func (g *Game) stream(name string) generators.NumGen {
+	splittable, ok := g.randGen.(generators.SplittableNumGen)
+	if !ok {
+		return g.randGen
+	}
+	if gen, ok := g.streams[name]; ok {
+		return gen
+	}
+	if g.streams == nil {
+		g.streams = make(map[string]generators.NumGen)
+	}
+	g.streams[name] = splittable.Split(name)
+	return g.streams[name]
+}
+
+//alienStream returns the random number stream of a subsystem for one alien
+func (g *Game) alienStream(subsystem, alien string) generators.NumGen {
+	return g.stream(subsystem + "/" + alien)
+}
+
+//alienNames returns the aliens on the map in the order set by the game's
+//Ordering. Aliens missing from the spawn order, e.g. put on the map by hand,
+//come last, sorted by name
+func (g *Game) alienNames() []string {
+	aliens := make([]string, 0, len(g.AlienLocations))
+	listed := make(map[string]bool, len(g.AlienLocations))
+	if g.order == OrderBySpawn {
+		for _, alien := range g.spawnOrder {
+			if _, ok := g.AlienLocations[alien]; ok && !listed[alien] {
+				listed[alien] = true
+				aliens = append(aliens, alien)
+			}
+		}
+	}
+	rest := make([]string, 0, len(g.AlienLocations)-len(aliens))
+	for alien := range g.AlienLocations {
+		if !listed[alien] {
+			rest = append(rest, alien)
+		}
+	}
+	sort.Strings(rest)
+	return append(aliens, rest...)
 }
 
 //GenMoves generates the moves for each aliens
-//The move for each alien is generated randomly
-//If the generated direction has no path to other node,
-//that alien will stay at the same city
+//The move for each alien is decided by its MovePolicy. By default one of
+//the four directions or the wormholes is picked randomly, and if the
+//generated direction has no path to other node, that alien will stay at
+//the same city
+//Aliens in transit and aliens staying where they are get no move
 func (g *Game) GenMoves() map[string]int {
 	moves := make(map[string]int)
-	for alien := range g.AlienLocations {
-		random := g.randGen.GenerateNum(4)
-		direction := 1 << random
-		moves[alien] = direction
+	for _, alien := range g.alienNames() {
+		if direction := g.nextMove(alien); direction != 0 {
+			moves[alien] = direction
+		}
 	}
 	return moves
 }
 
+//nextMove asks the alien's MovePolicy for its move, 0 when the alien is in
+//transit or stays where it is
+func (g *Game) nextMove(alien string) int {
+	if _, ok := g.Transits[alien]; ok {
+		return 0
+	}
+	return g.policyFor(alien).NextMove(g, alien, g.alienStream("movement", alien))
+}
+
+//moveAliens makes the move of every alien for the current turn. Simultaneous
+//moves are all decided before any of them is made. Sequential moves are
+//decided one by one, each when the alien's turn comes, so the alien sees
+//where the aliens before it went and the fights they started
+func (g *Game) moveAliens() {
+	if g.moveMode == MoveSimultaneous {
+		moves := g.GenMoves()
+		log.Printf("Move #%d: %v", g.turn, moves)
+		g.MakeMove(moves)
+		return
+	}
+	log.Printf("Move #%d", g.turn)
+	g.advanceTransits()
+	for _, alien := range g.alienNames() {
+		if _, ok := g.AlienLocations[alien]; !ok {
+			continue
+		}
+		if direction := g.nextMove(alien); direction != 0 {
+			g.moveAlone(alien, map[string]int{alien: direction})
+		}
+	}
+}
+
 //MakeMove updates the game state based on the moves input
 //moves are generated by some generator. It's a map between
 //alien's name and direction to move. Please see DirectionBitMap
 //in maps.go for definition of directions
+//Aliens in transit move one step closer to their destination first.
+//An alien taking a road which costs more than one move leaves its city
+//now and only arrives once the cost is paid
+//By default the aliens move one by one, and aliens meeting in the city one
+//of them arrives in fight there and then, before the next alien moves. See
+//MoveMode for other options
 func (g *Game) MakeMove(moves map[string]int) {
-	for alien, city := range g.AlienLocations {
-		direction, ok := moves[alien]
+	g.advanceTransits()
+	if g.moveMode == MoveSimultaneous {
+		g.makeSimultaneousMove(moves)
+		return
+	}
+	for _, alien := range g.alienNames() {
+		if _, ok := g.AlienLocations[alien]; ok {
+			g.moveAlone(alien, moves)
+		}
+	}
+}
+
+//moveAlone makes the move of one alien, then lets the aliens in the city
+//it arrives in fight, see fightIn
+func (g *Game) moveAlone(alien string, moves map[string]int) {
+	s := g.planStep(alien, moves)
+	if s == nil {
+		return
+	}
+	g.removeAlienFromCity(s.from.Name, s.alien)
+	g.takeStep(s)
+	if _, ok := g.Transits[alien]; !ok {
+		g.fightIn(s.to.Name)
+	}
+}
+
+//step is the move of one alien along a road
+type step struct {
+	alien     string
+	direction int
+	from, to  *generators.CityNode
+}
+
+//planStep returns the step the alien takes for its move, or nil
+//when the alien stays where it is, e.g. when the next city is destroyed
+func (g *Game) planStep(alien string, moves map[string]int) *step {
+	direction, ok := moves[alien]
+	if !ok {
+		return nil
+	}
+	if _, ok := g.Transits[alien]; ok {
+		return nil
+	}
+	cityNode, ok := g.CityMap[g.AlienLocations[alien]]
+	if !ok {
+		return nil
+	}
+	//one-way roads are only stored on the city they start from,
+	//so following the outgoing road is always allowed
+	nextCity := cityNode.Neighbor(direction)
+	if nextCity == nil || g.IsDestroyed(nextCity.Name) {
+		return nil
+	}
+	if !g.canAfford(alien, cityNode.Cost(direction)) {
+		log.Printf("Alien [%s] is stranded in <%s> without energy", alien, cityNode.Name)
+		return nil
+	}
+	return &step{alien: alien, direction: direction, from: cityNode, to: nextCity}
+}
+
+//takeStep puts an alien which left its city either onto the road
+//or, when the road costs one move, into the next city
+func (g *Game) takeStep(s *step) {
+	alien, city, nextCity := s.alien, s.from.Name, s.to
+	g.spend(alien, s.from.Cost(s.direction))
+	if cost := s.from.Cost(s.direction); cost > 1 {
+		log.Printf("Alien [%s] left <%s> for <%s>, arriving in %d moves", alien, city, nextCity.Name, cost)
+		if g.Transits == nil {
+			g.Transits = make(map[string]*Transit)
+		}
+		g.Transits[alien] = &Transit{From: city, To: nextCity.Name, Remaining: cost - 1}
+		return
+	}
+	log.Printf("Alien [%s] moved from <%s> to <%s>", alien, city, nextCity.Name)
+	nextCity.Aliens = append(nextCity.Aliens, alien)
+	g.AlienLocations[alien] = nextCity.Name
+}
+
+//advanceTransits moves the travelling aliens one step further
+//and places the ones which arrive into their destination, see destination.
+//Aliens which have nowhere to go die on the road
+func (g *Game) advanceTransits() {
+	for _, alien := range g.alienNames() {
+		transit, ok := g.Transits[alien]
 		if !ok {
 			continue
 		}
-		cityNode, ok := g.CityMap[city]
-		if !ok {
+		transit.Remaining--
+		if transit.Remaining > 0 {
 			continue
 		}
-		var nextCity *generators.CityNode
-		switch direction {
-		case generators.East:
-			if cityNode.East != nil {
-				nextCity = cityNode.East
-			}
-		case generators.West:
-			if cityNode.West != nil {
-				nextCity = cityNode.West
-			}
-		case generators.North:
-			if cityNode.North != nil {
-				nextCity = cityNode.North
-			}
-		case generators.South:
-			if cityNode.South != nil {
-				nextCity = cityNode.South
-			}
+		to := g.destination(transit)
+		if to == transit.From {
+			log.Printf("Alien [%s] found <%s> destroyed and went back to <%s>", alien, transit.To, to)
+		} else {
+			log.Printf("Alien [%s] arrived at <%s> from <%s>", alien, to, transit.From)
 		}
-		if nextCity != nil {
-			log.Printf("Alien [%s] moved from <%s> to <%s>", alien, city, nextCity.Name)
-			nextCity.Aliens = append(nextCity.Aliens, alien)
-			g.AlienLocations[alien] = nextCity.Name
+		node := g.CityMap[to]
+		node.Aliens = append(node.Aliens, alien)
+		g.AlienLocations[alien] = to
+		delete(g.Transits, alien)
+	}
+}
 
-			//Clear city node alien array
-			g.removeAlienFromCity(city, alien)
-		}
+//destination returns the city an alien on the road arrives in. Aliens never
+//enter a destroyed city, so it is the city the alien left when the one it
+//heads to is destroyed. The city the alien left stands, as destroying it
+//kills the aliens on its roads, see destroy
+func (g *Game) destination(transit *Transit) string {
+	if g.IsDestroyed(transit.To) {
+		return transit.From
 	}
+	return transit.To
 }
 
 func (g *Game) removeAlienFromCity(city, alien string) {
@@ -134,40 +409,115 @@ func (g *Game) removeAlienFromCity(city, alien string) {
 }
 
 //CheckAndDestroy checks whether there are aliens fighting in the same city
+//Humans in the city fight the aliens first, see WithHumans
+//The game's CollisionRule decides which aliens die and whether the city is
+//destroyed. Aliens killed before stay in the city's list, but only the living
+//ones take part, and a destroyed city cannot be destroyed again
 func (g *Game) CheckAndDestroy() {
-	for _, city := range g.AlienLocations {
-		aliens := g.CityMap[city].Aliens
-		if len(aliens) < 2 {
+	checked := make(map[string]bool)
+	for _, alien := range g.alienNames() {
+		city, ok := g.AlienLocations[alien]
+		if !ok || checked[city] {
+			//destroyed along with an earlier city, or city done already
 			continue
 		}
+		checked[city] = true
+		g.fightIn(city)
+	}
+}
+
+//fightIn lets the humans and the aliens in the city fight, following the
+//game's CollisionRule
+func (g *Game) fightIn(city string) {
+	aliens := g.defend(city, g.livingAliensIn(city))
+	if g.peaceful(aliens) {
+		return
+	}
+	dead, destroy := g.collisionRule().Resolve(g, city, aliens, g.stream("collision"))
+	destroy = destroy && !g.IsDestroyed(city)
+	if len(dead) == 0 && !destroy {
+		return
+	}
+	for _, alien := range dead {
+		delete(g.AlienLocations, alien)
+	}
+	if destroy && g.CityMap[city].Defense > 0 {
+		g.repel(city, aliens, dead)
+		return
+	}
+	if destroy {
 		log.Printf("!!!!!!City %s has been destroyed by aliens: %s !!!!!!", city, strings.Join(aliens, " "))
-		for _, alien := range aliens {
-			delete(g.AlienLocations, alien)
+		g.record(CityDestroyed, []string{city}, aliens, survivors(aliens, dead))
+		g.destroy(city, aliens, "aliens")
+		return
+	}
+	log.Printf("!!!!!!Aliens %s fought in city %s !!!!!!", strings.Join(aliens, " "), city)
+	g.record(CityFight, []string{city}, aliens, survivors(aliens, dead))
+}
+
+//locationOf returns the city an alien or a human is in
+func (g *Game) locationOf(agent string) string {
+	if city, ok := g.AlienLocations[agent]; ok {
+		return city
+	}
+	return g.HumanLocations[agent]
+}
+
+//livingAliensIn returns the aliens in the city which are still alive
+func (g *Game) livingAliensIn(city string) []string {
+	var aliens []string
+	for _, alien := range g.CityMap[city].Aliens {
+		if _, ok := g.AlienLocations[alien]; ok {
+			aliens = append(aliens, alien)
 		}
-		g.DestroyCity(city)
 	}
+	return aliens
 }
 
-//DestroyCity cuts the path(s) to neighbor(s)
-func (g *Game) DestroyCity(cn string) {
-	cityNode := g.CityMap[cn]
-	if len(cityNode.Aliens) < 2 {
-		log.Panic("city has less than 2 aliens")
+//survivors describes which of the aliens in a fight survived
+func survivors(aliens, dead []string) string {
+	var alive []string
+	for _, alien := range aliens {
+		if !contains(dead, alien) {
+			alive = append(alive, alien)
+		}
 	}
-	if cityNode.East != nil {
-		eastNeighbor := cityNode.East
-		cityNode.East, eastNeighbor.West = nil, nil
+	switch {
+	case len(alive) == 0:
+		return ""
+	case len(dead) == 0:
+		return "no alien died"
 	}
-	if cityNode.West != nil {
-		westNeighbor := cityNode.West
-		cityNode.West, westNeighbor.East = nil, nil
+	return strings.Join(alive, " ") + " survived"
+}
+
+//DestroyCity destroys the city with nobody responsible, see Destruction.
+//It does nothing to a city destroyed already
+func (g *Game) DestroyCity(cn string) {
+	g.destroy(cn, nil, "")
+}
+
+//cutOff cuts the path(s) to neighbor(s)
+//Roads and wormholes leading into the city are cut as well, including
+//one-way ones which are only known by the city they start from. Humans in
+//the city die, and the city catches fire when fire is used, see Fire
+func (g *Game) cutOff(cn string) {
+	cityNode := g.CityMap[cn]
+	g.ignite(cn)
+	for human, city := range g.HumanLocations {
+		if city == cn {
+			delete(g.HumanLocations, human)
+		}
 	}
-	if cityNode.North != nil {
-		northNeighbor := cityNode.North
-		cityNode.North, northNeighbor.South = nil, nil
+	for _, direction := range cityNode.Links() {
+		cityNode.SetNeighbor(direction, nil)
 	}
-	if cityNode.South != nil {
-		southNeighbor := cityNode.South
-		cityNode.South, southNeighbor.North = nil, nil
+	for _, node := range g.CityMap {
+		for _, direction := range node.Links() {
+			if node.Neighbor(direction) == cityNode {
+				node.SetNeighbor(direction, nil)
+			}
+		}
 	}
+	g.mapChanged()
 }
@@ -20,6 +20,7 @@ var (
 	west              = generators.West
 	north             = generators.North
 	south             = generators.South
+	wormhole          = generators.Wormhole
 	testingMasks      = [][]int{
 		{0, 0, 0},
 		{0, east | south, west | south},
@@ -45,12 +46,49 @@ func generateCityMap() map[string]*generators.CityNode {
 	return generators.GenerateCityMap(testingMasks, testingCityNames)
 }
 
-func generateGame() *Game {
+func generateGame(opts ...Option) *Game {
 	cityMap := generateCityMap()
 	alienLocations := map[string]string{testingAlien: testingCityNames[0]}
 	cityMap[testingCityNames[0]].Aliens = append(cityMap[testingCityNames[0]].Aliens, testingAlien)
-	return &Game{CityMap: cityMap, AlienLocations: alienLocations, randGen: fakeZeroGenerator}
+	game := &Game{CityMap: cityMap, OriginalMap: generators.CloneCityMap(cityMap), AlienLocations: alienLocations, randGen: fakeZeroGenerator}
+	for _, opt := range opts {
+		opt(game)
+	}
+	return game
+}
+
+//withoutAlien kills the alien of the testing game
+func withoutAlien(alien string) Option {
+	return func(g *Game) {
+		delete(g.AlienLocations, alien)
+	}
+}
+
+//withDestroyed destroys the cities of the testing game
+func withDestroyed(cities ...string) Option {
+	return func(g *Game) {
+		for _, city := range cities {
+			g.DestroyCity(city)
+		}
+	}
+}
 
+//withAlien puts another alien in the city of the testing game
+func withAlien(alien, city string) Option {
+	return func(g *Game) {
+		g.AlienLocations[alien] = city
+		g.CityMap[city].Aliens = append(g.CityMap[city].Aliens, alien)
+	}
+}
+
+//withSplitMap cuts the testing map in two halves without roads between them
+func withSplitMap() Option {
+	return func(g *Game) {
+		for _, i := range []int{0, 1} {
+			g.CityMap[testingCityNames[i]].South = nil
+			g.CityMap[testingCityNames[i+2]].North = nil
+		}
+	}
 }
 
 func TestNewGame(t *testing.T) {
@@ -113,3 +151,137 @@ func TestCheckAndDestroy(t *testing.T) {
 	game.CheckAndDestroy()
 	assert.Equal(0, len(game.AlienLocations))
 }
+
+func TestOneWayRoads(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	anotherAlien := generators.AlienNames[1]
+	start, end := game.CityMap[testingCityNames[0]], game.CityMap[testingCityNames[1]]
+	start.OneWay |= east
+	end.SetNeighbor(west, nil)
+	game.AlienLocations[anotherAlien] = end.Name
+	end.Aliens = append(end.Aliens, anotherAlien)
+
+	game.MakeMove(map[string]int{anotherAlien: west})
+	assert.Equal(end.Name, game.AlienLocations[anotherAlien])
+
+	//the alien fights the other one as soon as it arrives
+	game.MakeMove(map[string]int{testingAlien: east})
+	assert.True(game.IsDestroyed(end.Name))
+	assert.Nil(start.Neighbor(east))
+	assert.Nil(end.Neighbor(south))
+	assert.Nil(game.CityMap[testingCityNames[3]].Neighbor(north))
+}
+
+func TestTransit(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	start, end := game.CityMap[testingCityNames[0]], game.CityMap[testingCityNames[1]]
+	start.SetCost(east, 3)
+	end.SetCost(west, 3)
+	anotherAlien := generators.AlienNames[1]
+	game.AlienLocations[anotherAlien] = end.Name
+	end.Aliens = append(end.Aliens, anotherAlien)
+
+	game.MakeMove(map[string]int{testingAlien: east})
+	assert.Empty(start.Aliens)
+	assert.Equal(2, game.Transits[testingAlien].Remaining)
+	assert.NotContains(game.GenMoves(), testingAlien)
+
+	game.MakeMove(map[string]int{testingAlien: east})
+	game.CheckAndDestroy()
+	assert.Equal(1, game.Transits[testingAlien].Remaining)
+	assert.Equal(start.Name, game.AlienLocations[testingAlien])
+
+	game.MakeMove(map[string]int{})
+	assert.Empty(game.Transits)
+	assert.Equal(end.Name, game.AlienLocations[testingAlien])
+
+	game.CheckAndDestroy()
+	assert.Equal(0, len(game.AlienLocations))
+}
+
+func TestSeededGame(t *testing.T) {
+	assert := assert.New(t)
+
+	play := func(seed int64) *Game {
+		rng := generators.NewSeededNumGen(seed)
+		masks, _ := generators.GenerateDirectionMask(5, 5, rng)
+		cityNames, _ := generators.GenerateCityNames(rng, 25)
+		aliens, _ := generators.GenerateAlienNames(rng, 10)
+		game := NewGame(aliens, generators.GenerateCityMap(masks, cityNames), rng)
+		game.StartGame(50)
+		return game
+	}
+
+	first, second := play(3), play(3)
+	assert.Equal(first.AlienLocations, second.AlienLocations)
+	for cn, node := range first.CityMap {
+		assert.Equal(node.Aliens, second.CityMap[cn].Aliens)
+	}
+}
+
+func TestOrdering(t *testing.T) {
+	assert := assert.New(t)
+
+	aliens := []string{generators.AlienNames[2], generators.AlienNames[0], generators.AlienNames[1]}
+	game := NewGame(aliens, generateCityMap(), fakeZeroGenerator)
+	assert.Equal([]string{aliens[0], aliens[2], aliens[1]}, game.alienNames())
+
+	game = NewGame(aliens, generateCityMap(), fakeZeroGenerator, WithOrdering(OrderBySpawn))
+	assert.Equal(aliens, game.alienNames())
+	assert.Equal(generators.SortedCityNames(game.CityMap)[0], game.AlienLocations[aliens[0]])
+
+	delete(game.AlienLocations, aliens[2])
+	game.AlienLocations[generators.AlienNames[3]] = testingCityNames[0]
+	assert.Equal([]string{aliens[0], aliens[1], generators.AlienNames[3]}, game.alienNames())
+
+	order, err := ParseOrdering("spawn")
+	assert.Nil(err)
+	assert.Equal(OrderBySpawn, order)
+	_, err = ParseOrdering("random")
+	assert.NotNil(err)
+}
+
+func TestAlienStreams(t *testing.T) {
+	assert := assert.New(t)
+
+	aliens := []string{generators.AlienNames[0], generators.AlienNames[1]}
+	both := NewGame(aliens, generateCityMap(), generators.NewSeededNumGen(9))
+	single := NewGame(aliens[:1], generateCityMap(), generators.NewSeededNumGen(9))
+	reversed := NewGame(aliens, generateCityMap(), generators.NewSeededNumGen(9), WithOrdering(OrderBySpawn))
+	reversed.spawnOrder = []string{aliens[1], aliens[0]}
+
+	for i := 0; i < 10; i++ {
+		move := both.GenMoves()[aliens[0]]
+		assert.Equal(move, single.GenMoves()[aliens[0]])
+		assert.Equal(move, reversed.GenMoves()[aliens[0]])
+	}
+
+	//generators which cannot be split are shared by everyone
+	game := NewGame(aliens, generateCityMap(), fakeZeroGenerator)
+	assert.Equal(fakeZeroGenerator, game.alienStream("movement", aliens[0]))
+}
+
+func TestCityDestroyedEvent(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	anotherAlien := generators.AlienNames[1]
+	game.AlienLocations[anotherAlien] = testingCityNames[1]
+	game.CityMap[testingCityNames[1]].Aliens = append(game.CityMap[testingCityNames[1]].Aliens, anotherAlien)
+	game.turn = 7
+
+	game.MakeMove(map[string]int{testingAlien: east})
+	game.CheckAndDestroy()
+
+	events := game.EventsOf(CityDestroyed)
+	assert.Equal(1, len(events))
+	assert.Equal(7, events[0].Turn)
+	assert.Equal([]string{testingCityNames[1]}, events[0].Cities)
+	assert.ElementsMatch([]string{testingAlien, anotherAlien}, events[0].Aliens)
+	assert.Equal("move #7, city destroyed at PaintedHills by Raxomalik Yalmimin", events[0].String())
+	assert.Empty(game.EventsOf(RoadCrossing))
+}
@@ -0,0 +1,111 @@
+package games
+
+import (
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hatricker/alieninvasion/generators"
+)
+
+//WithHumans puts human defenders on random cities when the game starts.
+//Humans move with their own policy, see WithHumanPolicy, and take any road
+//in one move. When humans meet a lone alien in a city, they kill it. When
+//aliens outnumber the humans, the humans die. Otherwise neither side kills
+//the other, see defend
+func WithHumans(humans ...string) Option {
+	return func(g *Game) {
+		g.humans = append(g.humans, humans...)
+	}
+}
+
+//WithHumanPolicy sets the policy deciding the moves of the humans
+func WithHumanPolicy(policy MovePolicy) Option {
+	return func(g *Game) {
+		g.humanPolicy = policy
+	}
+}
+
+//Humans returns all human defenders put on the map, living or not
+func (g *Game) Humans() []string {
+	return append([]string{}, g.humans...)
+}
+
+//placeHumans puts the humans on random cities, several humans may share one
+func (g *Game) placeHumans() {
+	g.HumanLocations = make(map[string]string, len(g.humans))
+	cities := generators.SortedCityNames(g.CityMap)
+	if len(cities) == 0 {
+		return
+	}
+	gen := g.stream("humans")
+	for _, human := range g.humans {
+		g.HumanLocations[human] = cities[gen.GenerateNum(len(cities))]
+	}
+	log.Printf("Humans on the map: %v", g.HumanLocations)
+}
+
+//humanNames returns the living humans sorted by name
+func (g *Game) humanNames() []string {
+	humans := make([]string, 0, len(g.HumanLocations))
+	for human := range g.HumanLocations {
+		humans = append(humans, human)
+	}
+	sort.Strings(humans)
+	return humans
+}
+
+//humansIn returns the living humans in the city sorted by name
+func (g *Game) humansIn(city string) []string {
+	var humans []string
+	for _, human := range g.humanNames() {
+		if g.HumanLocations[human] == city {
+			humans = append(humans, human)
+		}
+	}
+	return humans
+}
+
+//moveHumans moves each human along the road picked by the human policy
+func (g *Game) moveHumans() {
+	policy := g.humanPolicy
+	if policy == nil {
+		policy = &UniformPolicy{}
+	}
+	for _, human := range g.humanNames() {
+		direction := policy.NextMove(g, human, g.alienStream("human", human))
+		node := g.CityMap[g.HumanLocations[human]]
+		if next := node.Neighbor(direction); next != nil {
+			log.Printf("Human [%s] moved from <%s> to <%s>", human, node.Name, next.Name)
+			g.HumanLocations[human] = next.Name
+		}
+	}
+}
+
+//defend makes the humans in the city fight the aliens in it and returns
+//the aliens left alive. The humans kill a lone alien and die when the
+//aliens outnumber them. Several aliens not outnumbering the humans hold
+//them off, and are left to fight each other
+func (g *Game) defend(city string, aliens []string) []string {
+	humans := g.humansIn(city)
+	if len(humans) == 0 || len(aliens) == 0 {
+		return aliens
+	}
+	if len(aliens) > len(humans) {
+		for _, human := range humans {
+			delete(g.HumanLocations, human)
+		}
+		log.Printf("!!!!!!Humans %s were killed by aliens %s in city %s !!!!!!",
+			strings.Join(humans, " "), strings.Join(aliens, " "), city)
+		g.record(HumanDefense, []string{city}, aliens, "humans "+strings.Join(humans, " ")+" killed")
+		return aliens
+	}
+	if len(aliens) > 1 {
+		return aliens
+	}
+	delete(g.AlienLocations, aliens[0])
+	log.Printf("!!!!!!Humans %s killed alien %s in city %s !!!!!!",
+		strings.Join(humans, " "), aliens[0], city)
+	g.record(HumanDefense, []string{city}, aliens, "alien killed by humans "+strings.Join(humans, " "))
+	return nil
+}
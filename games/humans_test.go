@@ -0,0 +1,75 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/hatricker/alieninvasion/generators"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHumanDefense(t *testing.T) {
+	assert := assert.New(t)
+
+	humans := []string{"Ripley", "Hicks"}
+	tests := []struct {
+		aliens, humans int
+		aliensLeft     int
+		humansLeft     int
+		destroyed      bool
+		outcome        string
+	}{
+		{1, 1, 0, 1, false, "alien killed by humans Ripley"},
+		{1, 2, 0, 2, false, "alien killed by humans Hicks Ripley"},
+		{2, 1, 0, 0, true, "humans Ripley killed"},
+		{3, 2, 0, 0, true, "humans Hicks Ripley killed"},
+		//two aliens hold two humans off, then destroy the city fighting each other
+		{2, 2, 0, 0, true, ""},
+	}
+
+	for _, tt := range tests {
+		game, _ := generateCrowdedGame(tt.aliens, WithHumans(humans[:tt.humans]...))
+		game.CheckAndDestroy()
+
+		assert.Equal(tt.aliensLeft, len(game.AlienLocations))
+		assert.Equal(tt.humansLeft, len(game.HumanLocations))
+		assert.Equal(tt.destroyed, game.IsDestroyed(testingCityNames[0]))
+		defense := game.EventsOf(HumanDefense)
+		if tt.outcome == "" {
+			assert.Empty(defense)
+			continue
+		}
+		assert.Equal(1, len(defense))
+		assert.Equal(tt.outcome, defense[0].Outcome)
+	}
+}
+
+func TestHumansElsewhere(t *testing.T) {
+	assert := assert.New(t)
+
+	game, _ := generateCrowdedGame(1, WithHumans("Ripley"))
+	game.HumanLocations["Ripley"] = testingCityNames[3]
+	game.CheckAndDestroy()
+	assert.Equal(1, len(game.AlienLocations))
+	assert.Equal(1, len(game.HumanLocations))
+	assert.Empty(game.Events)
+
+	game.DestroyCity(testingCityNames[3])
+	assert.Empty(game.HumanLocations)
+}
+
+func TestMoveHumans(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame([]string{generators.AlienNames[0]}, generateCityMap(), fakeZeroGenerator,
+		WithHumans("Ripley", "Hicks"), WithHumanPolicy(&RoadsPolicy{}))
+	assert.Equal([]string{"Ripley", "Hicks"}, game.Humans())
+	assert.Equal(map[string]string{"Ripley": testingCityNames[0], "Hicks": testingCityNames[0]}, game.HumanLocations)
+
+	game.moveHumans()
+	assert.Equal(map[string]string{"Ripley": testingCityNames[1], "Hicks": testingCityNames[1]}, game.HumanLocations)
+
+	//the uniform policy picks east, there is no road east any more
+	game.humanPolicy = nil
+	game.moveHumans()
+	assert.Equal(testingCityNames[1], game.HumanLocations["Ripley"])
+}
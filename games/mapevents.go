@@ -0,0 +1,188 @@
+package games
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hatricker/alieninvasion/generators"
+)
+
+//mapEventKinds holds the names of the map events
+var mapEventKinds = []string{"collapse", "meteor", "road"}
+
+//MapEvent changes the map during the game. Kind is "collapse" (a random
+//road or wormhole collapses), "meteor" (a random city left standing is
+//destroyed along with everyone in it) or "road" (a new two-way road opens
+//between two cities). It happens every Every moves, or when Every is not
+//set, at each move with the probability Chance (0-1)
+type MapEvent struct {
+	Kind   string  `json:"kind"`
+	Every  int     `json:"every"`
+	Chance float64 `json:"chance"`
+}
+
+//validate checks the map event can be scheduled
+func (e *MapEvent) validate() error {
+	if !contains(mapEventKinds, e.Kind) {
+		return fmt.Errorf("unknown map event %q", e.Kind)
+	}
+	if e.Every < 0 || e.Chance < 0 || e.Chance > 1 {
+		return fmt.Errorf("invalid schedule of map event %s", e.Kind)
+	}
+	if (e.Every > 0) == (e.Chance > 0) {
+		return fmt.Errorf("map event %s needs either a period or a chance", e.Kind)
+	}
+	return nil
+}
+
+//WithMapEvents schedules events changing the map, see MapEvent
+func WithMapEvents(events ...MapEvent) Option {
+	return func(g *Game) {
+		g.mapEvents = append(g.mapEvents, events...)
+	}
+}
+
+//changeMap applies the map events happening at the current move
+func (g *Game) changeMap() {
+	for i := range g.mapEvents {
+		e := &g.mapEvents[i]
+		gen := g.stream("mapevents/" + e.Kind)
+		if e.Every > 0 {
+			if g.turn == 0 || g.turn%e.Every != 0 {
+				continue
+			}
+		} else if !chance(gen, e.Chance) {
+			continue
+		}
+		switch e.Kind {
+		case "collapse":
+			g.collapseRoad(gen)
+		case "meteor":
+			g.strikeMeteor(gen)
+		case "road":
+			g.openRoad(gen)
+		}
+	}
+}
+
+//link is a road or wormhole leaving a city
+type link struct {
+	from      *generators.CityNode
+	direction int
+}
+
+//collapseRoad cuts a random road or wormhole, both ways when it is two-way
+func (g *Game) collapseRoad(gen generators.NumGen) {
+	var links []link
+	for _, city := range generators.SortedCityNames(g.CityMap) {
+		node := g.CityMap[city]
+		for _, direction := range node.Links() {
+			//two-way roads are listed once, by the city with the smaller name
+			if node.IsOneWay(direction) || node.Back(direction) == 0 || node.Name < node.Neighbor(direction).Name {
+				links = append(links, link{node, direction})
+			}
+		}
+	}
+	if len(links) == 0 {
+		return
+	}
+	l := links[gen.GenerateNum(len(links))]
+	to := l.from.Neighbor(l.direction)
+	if back := l.from.Back(l.direction); back != 0 && !l.from.IsOneWay(l.direction) {
+		to.SetNeighbor(back, nil)
+	}
+	l.from.SetNeighbor(l.direction, nil)
+	g.mapChanged()
+	log.Printf("!!!!!!The road between %s and %s collapsed !!!!!!", l.from.Name, to.Name)
+	g.record(RoadCollapsed, []string{l.from.Name, to.Name}, nil, "")
+}
+
+//strikeMeteor destroys a random city left standing, killing everyone in it
+//and on the roads leaving it
+func (g *Game) strikeMeteor(gen generators.NumGen) {
+	var cities []string
+	for _, city := range generators.SortedCityNames(g.CityMap) {
+		if !g.IsDestroyed(city) {
+			cities = append(cities, city)
+		}
+	}
+	if len(cities) == 0 {
+		return
+	}
+	city := cities[gen.GenerateNum(len(cities))]
+	log.Printf("!!!!!!City %s has been destroyed by a meteor !!!!!!", city)
+	g.wipeOut(city, "meteor")
+}
+
+//openRoad builds a two-way road between two cities left standing, leaving
+//a random city in a random direction it has no road in yet
+func (g *Game) openRoad(gen generators.NumGen) {
+	type opening struct {
+		city      string
+		direction int
+	}
+	var openings []opening
+	for _, city := range generators.SortedCityNames(g.CityMap) {
+		if g.IsDestroyed(city) {
+			continue
+		}
+		for _, direction := range generators.DirectionBitMap {
+			if g.CityMap[city].Neighbor(direction) == nil {
+				openings = append(openings, opening{city, direction})
+			}
+		}
+	}
+	if len(openings) == 0 {
+		return
+	}
+	o := openings[gen.GenerateNum(len(openings))]
+	back := generators.Opposite(o.direction)
+	var ends []string
+	for _, other := range openings {
+		if other.direction == back && other.city != o.city {
+			ends = append(ends, other.city)
+		}
+	}
+	if len(ends) == 0 {
+		return
+	}
+	from, to := g.CityMap[o.city], g.CityMap[ends[gen.GenerateNum(len(ends))]]
+	from.SetNeighbor(o.direction, to)
+	to.SetNeighbor(back, from)
+	g.mapChanged()
+	log.Printf("!!!!!!A new road opened between %s and %s !!!!!!", from.Name, to.Name)
+	g.record(RoadOpened, []string{from.Name, to.Name}, nil, "")
+}
+
+//ParseMapEvents parses a comma separated list of map events written as
+//<kind>:<chance> or <kind>:every=<moves>, e.g. "collapse:0.01,meteor:every=50"
+func ParseMapEvents(spec string) ([]MapEvent, error) {
+	var events []MapEvent
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		parts := strings.Split(s, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid map event %q, expecting <kind>:<chance> or <kind>:every=<moves>", s)
+		}
+		e := MapEvent{Kind: parts[0]}
+		var err error
+		if strings.HasPrefix(parts[1], "every=") {
+			e.Every, err = strconv.Atoi(strings.TrimPrefix(parts[1], "every="))
+		} else {
+			e.Chance, err = strconv.ParseFloat(parts[1], 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid map event %q, %v", s, err)
+		}
+		if err := e.validate(); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
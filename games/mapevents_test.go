@@ -0,0 +1,84 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollapseRoad(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	game.collapseRoad(fakeZeroGenerator)
+	assert.Nil(game.CityMap[testingCityNames[0]].East)
+	assert.Nil(game.CityMap[testingCityNames[1]].West)
+	assert.Equal([]Event{{
+		Kind:   RoadCollapsed,
+		Cities: []string{testingCityNames[0], testingCityNames[1]},
+		Aliens: []string{},
+	}}, game.Events)
+
+	//one-way roads collapse one way only
+	game.CityMap[testingCityNames[0]].OneWay |= south
+	game.collapseRoad(fakeZeroGenerator)
+	assert.Nil(game.CityMap[testingCityNames[0]].South)
+	assert.NotNil(game.CityMap[testingCityNames[2]].North)
+}
+
+func TestStrikeMeteor(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	game.strikeMeteor(fakeZeroGenerator)
+	assert.Empty(game.AlienLocations)
+	assert.True(game.IsDestroyed(testingCityNames[0]))
+	assert.Empty(game.CityMap[testingCityNames[0]].Links())
+	assert.Equal("meteor", game.Events[0].Outcome)
+	assert.Equal([]string{testingAlien}, game.Events[0].Aliens)
+
+	game.strikeMeteor(fakeZeroGenerator)
+	assert.True(game.IsDestroyed(testingCityNames[2]))
+}
+
+func TestOpenRoad(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	game.openRoad(fakeZeroGenerator)
+	from, to := game.CityMap[testingCityNames[0]], game.CityMap[testingCityNames[1]]
+	assert.Equal(to, from.West)
+	assert.Equal(from, to.East)
+	assert.Equal([]Event{{
+		Kind:   RoadOpened,
+		Cities: []string{testingCityNames[0], testingCityNames[1]},
+		Aliens: []string{},
+	}}, game.Events)
+}
+
+func TestChangeMapSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	WithMapEvents(MapEvent{Kind: "meteor", Every: 2}, MapEvent{Kind: "collapse", Chance: 1})(game)
+	for turn := 0; turn < 3; turn++ {
+		game.turn = turn
+		game.changeMap()
+	}
+	assert.Equal(1, len(game.EventsOf(CityDestroyed)))
+	assert.Equal(2, game.EventsOf(CityDestroyed)[0].Turn)
+	assert.Equal(3, len(game.EventsOf(RoadCollapsed)))
+}
+
+func TestParseMapEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	events, err := ParseMapEvents("collapse:0.01, meteor:every=50")
+	assert.Nil(err)
+	assert.Equal([]MapEvent{{Kind: "collapse", Chance: 0.01}, {Kind: "meteor", Every: 50}}, events)
+
+	for _, spec := range []string{"collapse", "flood:0.1", "road:2", "road:-0.1", "road:0", "meteor:every=x", "meteor:every=-1", "road:0.1:2"} {
+		_, err := ParseMapEvents(spec)
+		assert.NotNil(err, spec)
+	}
+}
@@ -0,0 +1,189 @@
+package games
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+//MoveMode defines how the moves of all aliens in one turn are applied
+type MoveMode int
+
+const (
+	//MoveSequential moves the aliens one by one in the game's Ordering. Each
+	//alien fights the aliens in the city it arrives in before the next one
+	//moves, so later aliens see the moves and fights of earlier ones. It is
+	//the default
+	MoveSequential MoveMode = iota
+	//MoveSimultaneous takes all aliens out of their cities before any of them
+	//arrives, so all steps are applied at once on the same snapshot of the map
+	//and aliens only fight once all of them moved
+	MoveSimultaneous
+)
+
+//CrossingRule decides what happens when aliens meet head-on, that is they
+//set off along the same road from opposite ends in the same move. It only
+//applies to MoveSimultaneous, moving one by one an alien arrives in the
+//other's city before that one leaves
+type CrossingRule int
+
+const (
+	//CrossPassThrough lets the aliens pass each other unnoticed. It is the default
+	CrossPassThrough CrossingRule = iota
+	//CrossFight makes the aliens fight on the road, none of them survives
+	CrossFight
+	//CrossCutRoad makes the aliens fight over the road, which is destroyed.
+	//The aliens survive and stay in the cities they came from
+	CrossCutRoad
+	//CrossReport records the crossing as an event, but the aliens pass each other
+	CrossReport
+)
+
+//WithMoveMode sets how the moves of one turn are applied
+func WithMoveMode(mode MoveMode) Option {
+	return func(g *Game) {
+		g.moveMode = mode
+	}
+}
+
+//WithCrossingRule sets what happens to aliens meeting head-on on a road
+func WithCrossingRule(rule CrossingRule) Option {
+	return func(g *Game) {
+		g.crossing = rule
+	}
+}
+
+//ParseMoveMode converts the name of a move mode, "sequential" or "simultaneous", to its value
+func ParseMoveMode(name string) (MoveMode, error) {
+	switch name {
+	case "sequential":
+		return MoveSequential, nil
+	case "simultaneous":
+		return MoveSimultaneous, nil
+	}
+	return MoveSequential, fmt.Errorf("unknown move mode %q", name)
+}
+
+//crossingNames maps the names of crossing rules to their values
+var crossingNames = map[string]CrossingRule{
+	"pass":   CrossPassThrough,
+	"fight":  CrossFight,
+	"cut":    CrossCutRoad,
+	"report": CrossReport,
+}
+
+//ParseCrossingRule converts the name of a crossing rule, "pass", "fight",
+//"cut" or "report", to its value
+func ParseCrossingRule(name string) (CrossingRule, error) {
+	if rule, ok := crossingNames[name]; ok {
+		return rule, nil
+	}
+	return CrossPassThrough, fmt.Errorf("unknown crossing rule %q", name)
+}
+
+//makeSimultaneousMove plans the steps of all aliens first, so that aliens
+//meeting head-on on a road can be found, see CrossingRule. It then takes
+//all aliens out of their cities before any of them arrives, so every step
+//sees the same snapshot of the map
+func (g *Game) makeSimultaneousMove(moves map[string]int) {
+	var steps []*step
+	for _, alien := range g.alienNames() {
+		if s := g.planStep(alien, moves); s != nil {
+			steps = append(steps, s)
+		}
+	}
+	steps = g.resolveCrossings(steps)
+	for _, s := range steps {
+		g.removeAlienFromCity(s.from.Name, s.alien)
+	}
+	for _, s := range steps {
+		g.takeStep(s)
+	}
+}
+
+//roadKey identifies the road or wormhole a step is on, the same for both directions
+func roadKey(s *step) string {
+	if s.from.Name < s.to.Name {
+		return fmt.Sprintf("%s|%s|%d", s.from.Name, s.to.Name, s.direction)
+	}
+	return fmt.Sprintf("%s|%s|%d", s.to.Name, s.from.Name, s.from.Back(s.direction))
+}
+
+//resolveCrossings finds the roads travelled in both directions in this move
+//and applies the game's CrossingRule to the aliens on them. It returns the
+//steps which are still to be taken
+func (g *Game) resolveCrossings(steps []*step) []*step {
+	if g.crossing == CrossPassThrough {
+		return steps
+	}
+	//keys are worked out before any road is cut, as cutting changes them
+	keys := make([]string, len(steps))
+	roads := make(map[string][]*step)
+	for i, s := range steps {
+		keys[i] = roadKey(s)
+		roads[keys[i]] = append(roads[keys[i]], s)
+	}
+
+	left := steps[:0]
+	for i, s := range steps {
+		onRoad := roads[keys[i]]
+		if !g.crossedOnRoad(onRoad) {
+			left = append(left, s)
+			continue
+		}
+		if onRoad[0] == s {
+			g.crossOnRoad(onRoad)
+		}
+		switch g.crossing {
+		case CrossFight:
+			g.removeAlienFromCity(s.from.Name, s.alien)
+			delete(g.AlienLocations, s.alien)
+		case CrossReport:
+			left = append(left, s)
+		}
+	}
+	return left
+}
+
+//crossOnRoad reports aliens meeting head-on on a road and cuts the road
+//when the crossing rule says so
+func (g *Game) crossOnRoad(onRoad []*step) {
+	first := onRoad[0]
+	aliens := make([]string, 0, len(onRoad))
+	for _, s := range onRoad {
+		aliens = append(aliens, s.alien)
+	}
+	cities := []string{first.from.Name, first.to.Name}
+
+	switch g.crossing {
+	case CrossFight:
+		log.Printf("!!!!!!Aliens %s killed each other on the road between %s and %s !!!!!!",
+			strings.Join(aliens, " "), cities[0], cities[1])
+		g.record(RoadCrossing, cities, aliens, "aliens killed")
+	case CrossCutRoad:
+		log.Printf("!!!!!!Aliens %s destroyed the road between %s and %s !!!!!!",
+			strings.Join(aliens, " "), cities[0], cities[1])
+		g.record(RoadCrossing, cities, aliens, "road destroyed")
+		if back := first.from.Back(first.direction); back != 0 {
+			first.to.SetNeighbor(back, nil)
+		}
+		first.from.SetNeighbor(first.direction, nil)
+		g.mapChanged()
+	case CrossReport:
+		log.Printf("Aliens %s passed each other on the road between %s and %s",
+			strings.Join(aliens, " "), cities[0], cities[1])
+		g.record(RoadCrossing, cities, aliens, "aliens passed")
+	}
+}
+
+//crossedOnRoad tells whether the steps on one road go both ways
+//with aliens of different factions on it
+func (g *Game) crossedOnRoad(onRoad []*step) bool {
+	aliens := make([]string, 0, len(onRoad))
+	both := false
+	for _, s := range onRoad {
+		aliens = append(aliens, s.alien)
+		both = both || s.from != onRoad[0].from
+	}
+	return both && !g.peaceful(aliens)
+}
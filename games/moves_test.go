@@ -0,0 +1,196 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/hatricker/alieninvasion/generators"
+	"github.com/stretchr/testify/assert"
+)
+
+//generateSwapGame puts two aliens on the ends of the road between
+//the first two testing cities
+func generateSwapGame(opts ...Option) (*Game, []string) {
+	aliens := []string{generators.AlienNames[0], generators.AlienNames[1]}
+	cityMap := generateCityMap()
+	game := NewGame(nil, cityMap, fakeZeroGenerator, opts...)
+	for i, alien := range aliens {
+		game.AlienLocations[alien] = testingCityNames[i]
+		cityMap[testingCityNames[i]].Aliens = append(cityMap[testingCityNames[i]].Aliens, alien)
+	}
+	return game, aliens
+}
+
+func TestSimultaneousMove(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		opts      []Option
+		survivors int
+	}{
+		{[]Option{WithMoveMode(MoveSimultaneous)}, 2},
+		{[]Option{WithMoveMode(MoveSimultaneous), WithCrossingRule(CrossFight)}, 0},
+		//moving one by one, the first alien arrives before the other one leaves
+		{nil, 0},
+		{[]Option{WithCrossingRule(CrossFight)}, 0},
+	}
+
+	for _, tt := range tests {
+		game, aliens := generateSwapGame(tt.opts...)
+		game.MakeMove(map[string]int{aliens[0]: east, aliens[1]: west})
+		assert.Equal(tt.survivors, len(game.AlienLocations))
+		if tt.survivors > 0 {
+			assert.Equal(testingCityNames[1], game.AlienLocations[aliens[0]])
+			assert.Equal(testingCityNames[0], game.AlienLocations[aliens[1]])
+		} else if game.moveMode == MoveSimultaneous {
+			assert.Empty(game.CityMap[testingCityNames[0]].Aliens)
+			assert.Empty(game.CityMap[testingCityNames[1]].Aliens)
+		} else {
+			assert.Empty(game.EventsOf(RoadCrossing))
+			assert.Equal(1, len(game.EventsOf(CityDestroyed)))
+		}
+	}
+}
+
+func TestSequentialMove(t *testing.T) {
+	assert := assert.New(t)
+
+	aliens := []string{"Degir", "Raxomalik", "Yalmimin"}
+	for _, mode := range []MoveMode{MoveSequential, MoveSimultaneous} {
+		game := NewGame(nil, generateCityMap(), fakeZeroGenerator, WithMoveMode(mode))
+		for i, city := range []string{testingCityNames[0], testingCityNames[1], testingCityNames[3]} {
+			withAlien(aliens[i], city)(game)
+		}
+		game.MakeMove(map[string]int{aliens[0]: east, aliens[1]: south, aliens[2]: north})
+		game.CheckAndDestroy()
+
+		assert.True(game.IsDestroyed(testingCityNames[1]), mode)
+		if mode == MoveSequential {
+			//the second alien dies before it leaves, the third one finds the
+			//city it heads to destroyed and stays
+			assert.Equal(map[string]string{aliens[2]: testingCityNames[3]}, game.AlienLocations)
+			assert.Equal([]string{aliens[1], aliens[0]}, game.EventsOf(CityDestroyed)[0].Aliens)
+		} else {
+			assert.Equal(map[string]string{aliens[1]: testingCityNames[3]}, game.AlienLocations)
+			assert.Equal([]string{aliens[0], aliens[2]}, game.EventsOf(CityDestroyed)[0].Aliens)
+		}
+	}
+}
+
+func TestSimultaneousMoveOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	game, aliens := generateSwapGame(WithMoveMode(MoveSimultaneous), WithCrossingRule(CrossFight))
+	//both aliens take parallel roads south into different cities, no crossing
+	game.MakeMove(map[string]int{aliens[0]: south, aliens[1]: south})
+	assert.Equal(testingCityNames[2], game.AlienLocations[aliens[0]])
+	assert.Equal(testingCityNames[3], game.AlienLocations[aliens[1]])
+
+	game.MakeMove(map[string]int{aliens[0]: east, aliens[1]: north})
+	assert.Equal([]string{aliens[0]}, game.CityMap[testingCityNames[3]].Aliens)
+	assert.Equal([]string{aliens[1]}, game.CityMap[testingCityNames[1]].Aliens)
+
+	game.MakeMove(map[string]int{aliens[0]: north, aliens[1]: west})
+	assert.Equal(testingCityNames[1], game.AlienLocations[aliens[0]])
+	assert.Equal(testingCityNames[0], game.AlienLocations[aliens[1]])
+}
+
+func TestCrossingRules(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		rule      CrossingRule
+		survivors int
+		swapped   bool
+		roadCut   bool
+		outcome   string
+	}{
+		{CrossPassThrough, 2, true, false, ""},
+		{CrossFight, 0, false, false, "aliens killed"},
+		{CrossCutRoad, 2, false, true, "road destroyed"},
+		{CrossReport, 2, true, false, "aliens passed"},
+	}
+
+	for _, tt := range tests {
+		game, aliens := generateSwapGame(WithMoveMode(MoveSimultaneous), WithCrossingRule(tt.rule))
+		game.MakeMove(map[string]int{aliens[0]: east, aliens[1]: west})
+		game.CheckAndDestroy()
+
+		assert.Equal(tt.survivors, len(game.AlienLocations))
+		assert.Empty(game.EventsOf(CityDestroyed))
+		if tt.swapped {
+			assert.Equal(testingCityNames[1], game.AlienLocations[aliens[0]])
+			assert.Equal(testingCityNames[0], game.AlienLocations[aliens[1]])
+		} else if tt.survivors > 0 {
+			assert.Equal(testingCityNames[0], game.AlienLocations[aliens[0]])
+			assert.Equal(testingCityNames[1], game.AlienLocations[aliens[1]])
+		}
+		assert.Equal(tt.roadCut, game.CityMap[testingCityNames[0]].East == nil)
+		assert.Equal(tt.roadCut, game.CityMap[testingCityNames[1]].West == nil)
+
+		crossings := game.EventsOf(RoadCrossing)
+		if tt.outcome == "" {
+			assert.Empty(crossings)
+			continue
+		}
+		assert.Equal([]Event{{
+			Kind:    RoadCrossing,
+			Cities:  []string{testingCityNames[1], testingCityNames[0]},
+			Aliens:  []string{aliens[1], aliens[0]},
+			Outcome: tt.outcome,
+		}}, crossings)
+	}
+}
+
+func TestParseMoveSettings(t *testing.T) {
+	assert := assert.New(t)
+
+	mode, err := ParseMoveMode("simultaneous")
+	assert.Nil(err)
+	assert.Equal(MoveSimultaneous, mode)
+	_, err = ParseMoveMode("random")
+	assert.NotNil(err)
+
+	rule, err := ParseCrossingRule("fight")
+	assert.Nil(err)
+	assert.Equal(CrossFight, rule)
+	rule, err = ParseCrossingRule("cut")
+	assert.Nil(err)
+	assert.Equal(CrossCutRoad, rule)
+	_, err = ParseCrossingRule("dance")
+	assert.NotNil(err)
+}
+
+func TestWormholeMoves(t *testing.T) {
+	assert := assert.New(t)
+
+	game, aliens := generateSwapGame(WithMoveMode(MoveSimultaneous), WithCrossingRule(CrossCutRoad))
+	from, to := game.CityMap[testingCityNames[0]], game.CityMap[testingCityNames[3]]
+	from.SetNeighbor(wormhole, to)
+	to.SetNeighbor(wormhole, from)
+	assert.Equal([]int{east, south, wormhole}, game.roadsFrom(aliens[0]))
+
+	game.MakeMove(map[string]int{aliens[0]: wormhole})
+	assert.Equal(testingCityNames[3], game.AlienLocations[aliens[0]])
+
+	//aliens meeting head-on in a wormhole cut it
+	game.MakeMove(map[string]int{aliens[1]: west})
+	game.MakeMove(map[string]int{aliens[0]: wormhole, aliens[1]: wormhole})
+	assert.Equal(1, len(game.EventsOf(RoadCrossing)))
+	assert.Equal(testingCityNames[3], game.AlienLocations[aliens[0]])
+	assert.Nil(from.Neighbor(wormhole))
+	assert.Nil(to.Neighbor(wormhole))
+}
+
+func TestDestroyCitySeversWormholes(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	from, to := game.CityMap[testingCityNames[0]], game.CityMap[testingCityNames[3]]
+	from.SetNeighbor(wormhole, to)
+	to.SetNeighbor(wormhole, from)
+	to.SetNeighbor(wormhole<<1, game.CityMap[testingCityNames[1]])
+
+	game.DestroyCity(testingCityNames[3])
+	assert.Empty(to.Links())
+	assert.Equal([]int{east, south}, from.Links())
+}
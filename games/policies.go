@@ -0,0 +1,350 @@
+package games
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hatricker/alieninvasion/generators"
+	"github.com/hatricker/alieninvasion/graph"
+)
+
+//MovePolicy decides where an alien goes in its next move
+//NextMove returns a direction (see DirectionBitMap in maps.go), or 0 when
+//the alien stays where it is. gen is the alien's own random number stream
+type MovePolicy interface {
+	NextMove(g *Game, alien string, gen generators.NumGen) int
+}
+
+//UniformPolicy picks one of the four directions or of the wormholes of the
+//alien's city with the same chance, even when there is no road that way. It
+//is the default policy
+type UniformPolicy struct {
+}
+
+//NextMove implements MovePolicy interface
+func (p *UniformPolicy) NextMove(g *Game, alien string, gen generators.NumGen) int {
+	var wormholes []int
+	if node, ok := g.CityMap[g.locationOf(alien)]; ok {
+		for _, direction := range node.Links() {
+			if direction >= generators.Wormhole {
+				wormholes = append(wormholes, direction)
+			}
+		}
+	}
+	compass := len(generators.DirectionBitMap)
+	i := gen.GenerateNum(compass + len(wormholes))
+	if i >= compass {
+		return wormholes[i-compass]
+	}
+	return generators.DirectionBitMap[i]
+}
+
+//RoadsPolicy picks one of the roads leaving the alien's city with the same chance
+type RoadsPolicy struct {
+}
+
+//NextMove implements MovePolicy interface
+func (p *RoadsPolicy) NextMove(g *Game, alien string, gen generators.NumGen) int {
+	return pickRoad(g.roadsFrom(alien), gen)
+}
+
+//LazyPolicy keeps the alien where it is with the chance of Stay (0 to 1),
+//otherwise it lets Inner decide, a RoadsPolicy when not set
+type LazyPolicy struct {
+	Stay  float64
+	Inner MovePolicy
+}
+
+//NextMove implements MovePolicy interface
+func (p *LazyPolicy) NextMove(g *Game, alien string, gen generators.NumGen) int {
+	if chance(gen, p.Stay) {
+		return 0
+	}
+	if p.Inner == nil {
+		return (&RoadsPolicy{}).NextMove(g, alien, gen)
+	}
+	return p.Inner.NextMove(g, alien, gen)
+}
+
+//DriftPolicy takes the road in Direction with the chance of Bias (0 to 1)
+//when there is one. Otherwise it picks any of the roads
+type DriftPolicy struct {
+	Direction int
+	Bias      float64
+}
+
+//NextMove implements MovePolicy interface
+func (p *DriftPolicy) NextMove(g *Game, alien string, gen generators.NumGen) int {
+	roads := g.roadsFrom(alien)
+	for _, direction := range roads {
+		if direction == p.Direction && chance(gen, p.Bias) {
+			return direction
+		}
+	}
+	return pickRoad(roads, gen)
+}
+
+//AvoidVisitedPolicy prefers roads to cities the alien has not been to in its
+//last Memory moves. When all neighbors were visited recently, any road is taken
+type AvoidVisitedPolicy struct {
+	Memory  int
+	visited map[string][]string
+}
+
+//NewAvoidVisitedPolicy returns an AvoidVisitedPolicy object remembering the
+//last memory cities of each alien
+func NewAvoidVisitedPolicy(memory int) *AvoidVisitedPolicy {
+	return &AvoidVisitedPolicy{Memory: memory, visited: make(map[string][]string)}
+}
+
+//NextMove implements MovePolicy interface
+func (p *AvoidVisitedPolicy) NextMove(g *Game, alien string, gen generators.NumGen) int {
+	if p.visited == nil {
+		p.visited = make(map[string][]string)
+	}
+	city := g.locationOf(alien)
+	visited := append(p.visited[alien], city)
+	if len(visited) > p.Memory {
+		visited = visited[len(visited)-p.Memory:]
+	}
+	p.visited[alien] = visited
+
+	roads := g.roadsFrom(alien)
+	fresh := make([]int, 0, len(roads))
+	for _, direction := range roads {
+		if !contains(visited, g.CityMap[city].Neighbor(direction).Name) {
+			fresh = append(fresh, direction)
+		}
+	}
+	if len(fresh) > 0 {
+		return pickRoad(fresh, gen)
+	}
+	return pickRoad(roads, gen)
+}
+
+//SeekPolicy hunts the other aliens. It takes the first road of the cheapest
+//way to the nearest other city with an alien it would fight within Radius
+//moves, 0 meaning no limit. Aliens of its own faction are not hunted
+//With no such alien in sight it picks any of the roads
+type SeekPolicy struct {
+	Radius int
+}
+
+//NextMove implements MovePolicy interface
+func (p *SeekPolicy) NextMove(g *Game, alien string, gen generators.NumGen) int {
+	hostile := g.occupiedCities(alien, true)
+	start := g.CityMap[g.locationOf(alien)]
+	reach, ok := graph.Nearest(start, p.Radius, func(city *generators.CityNode) bool {
+		return city != start && hostile[city.Name]
+	})
+	if ok {
+		return reach.FirstStep
+	}
+	return pickRoad(g.roadsFrom(alien), gen)
+}
+
+//FleePolicy avoids the other aliens within Radius moves, 0 meaning no limit
+//It takes the road to the city the aliens in sight need the most moves to
+//reach, or stays when no road leads further away from them. With no alien
+//in sight it picks any of the roads
+type FleePolicy struct {
+	Radius int
+}
+
+//NextMove implements MovePolicy interface
+func (p *FleePolicy) NextMove(g *Game, alien string, gen generators.NumGen) int {
+	occupied := g.occupiedCities(alien, false)
+	start := g.CityMap[g.locationOf(alien)]
+	var seen []*generators.CityNode
+	for _, r := range graph.Explore(start, p.Radius) {
+		if occupied[r.City.Name] {
+			seen = append(seen, r.City)
+		}
+	}
+	roads := g.roadsFrom(alien)
+	if len(seen) == 0 {
+		return pickRoad(roads, gen)
+	}
+
+	distances := graph.Distances(seen, 0)
+	distance := func(city *generators.CityNode) int {
+		if d, ok := distances[city.Name]; ok {
+			return d
+		}
+		//the aliens cannot get there at all
+		return int(^uint(0) >> 1)
+	}
+	best, bestDistance := 0, distance(start)
+	for _, direction := range roads {
+		if d := distance(start.Neighbor(direction)); d > bestDistance {
+			best, bestDistance = direction, d
+		}
+	}
+	return best
+}
+
+//WithPolicy sets the policy deciding the moves of all aliens
+//without a policy of their own
+func WithPolicy(policy MovePolicy) Option {
+	return func(g *Game) {
+		g.policy = policy
+	}
+}
+
+//WithAlienPolicy sets the policy deciding the moves of one alien
+func WithAlienPolicy(alien string, policy MovePolicy) Option {
+	return func(g *Game) {
+		if g.alienPolicies == nil {
+			g.alienPolicies = make(map[string]MovePolicy)
+		}
+		g.alienPolicies[alien] = policy
+	}
+}
+
+//policyFor returns the policy deciding the moves of the alien
+func (g *Game) policyFor(alien string) MovePolicy {
+	if policy, ok := g.alienPolicies[alien]; ok {
+		return policy
+	}
+	if g.policy != nil {
+		return g.policy
+	}
+	return &UniformPolicy{}
+}
+
+//roadsFrom returns the directions of the roads leaving the alien's city
+func (g *Game) roadsFrom(alien string) []int {
+	node, ok := g.CityMap[g.locationOf(alien)]
+	if !ok {
+		return nil
+	}
+	return roadsOf(node)
+}
+
+//roadsOf returns the directions of the roads and wormholes leaving the city
+func roadsOf(node *generators.CityNode) []int {
+	return node.Links()
+}
+
+//occupiedCities returns the cities with living aliens other than alien in them,
+//only those with aliens alien would fight when hostile is set
+//Aliens in transit are on a road, not in a city
+func (g *Game) occupiedCities(alien string, hostile bool) map[string]bool {
+	occupied := make(map[string]bool)
+	for other, city := range g.AlienLocations {
+		if _, ok := g.Transits[other]; ok || other == alien {
+			continue
+		}
+		if !hostile || !g.peaceful([]string{alien, other}) {
+			occupied[city] = true
+		}
+	}
+	return occupied
+}
+
+//pickRoad picks one of the roads with the same chance, 0 when there is none
+func pickRoad(roads []int, gen generators.NumGen) int {
+	if len(roads) == 0 {
+		return 0
+	}
+	return roads[gen.GenerateNum(len(roads))]
+}
+
+//chance returns true with the probability p (0 to 1)
+func chance(gen generators.NumGen, p float64) bool {
+	const scale = 1000000
+	return float64(gen.GenerateNum(scale)) < p*scale
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+//ParsePolicy builds a policy from its description:
+//"uniform", "roads", "lazy:<stay chance>", "drift:<direction>:<bias>",
+//"avoid:<memory>", "seek[:<radius>]" or "flee[:<radius>]",
+//e.g. "lazy:0.5", "drift:east:0.8" or "seek:3"
+func ParsePolicy(spec string) (MovePolicy, error) {
+	fields := strings.Split(spec, ":")
+	switch {
+	case fields[0] == "uniform" && len(fields) == 1:
+		return &UniformPolicy{}, nil
+	case fields[0] == "roads" && len(fields) == 1:
+		return &RoadsPolicy{}, nil
+	case fields[0] == "lazy" && len(fields) == 2:
+		stay, err := parseProbability(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		return &LazyPolicy{Stay: stay, Inner: &RoadsPolicy{}}, nil
+	case fields[0] == "drift" && len(fields) == 3:
+		direction := 0
+		for i, name := range generators.DirectionNames {
+			if name == fields[1] {
+				direction = generators.DirectionBitMap[i]
+			}
+		}
+		if direction == 0 {
+			return nil, fmt.Errorf("unknown direction %q in policy %q", fields[1], spec)
+		}
+		bias, err := parseProbability(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		return &DriftPolicy{Direction: direction, Bias: bias}, nil
+	case fields[0] == "avoid" && len(fields) == 2:
+		memory, err := strconv.Atoi(fields[1])
+		if err != nil || memory < 1 {
+			return nil, fmt.Errorf("invalid memory %q in policy %q", fields[1], spec)
+		}
+		return NewAvoidVisitedPolicy(memory), nil
+	case (fields[0] == "seek" || fields[0] == "flee") && len(fields) <= 2:
+		radius := 0
+		if len(fields) == 2 {
+			var err error
+			if radius, err = strconv.Atoi(fields[1]); err != nil || radius < 0 {
+				return nil, fmt.Errorf("invalid radius %q in policy %q", fields[1], spec)
+			}
+		}
+		if fields[0] == "seek" {
+			return &SeekPolicy{Radius: radius}, nil
+		}
+		return &FleePolicy{Radius: radius}, nil
+	}
+	return nil, fmt.Errorf("unknown policy %q", spec)
+}
+
+func parseProbability(s string) (float64, error) {
+	p, err := strconv.ParseFloat(s, 64)
+	if err != nil || p < 0 || p > 1 {
+		return 0, fmt.Errorf("invalid probability %q, it must be within 0-1", s)
+	}
+	return p, nil
+}
+
+//ParseAlienPolicies parses a comma separated list of <alien>=<policy>
+//pairs, e.g. "Zidane=lazy:0.5,Salah=roads", see ParsePolicy
+func ParseAlienPolicies(spec string) (map[string]MovePolicy, error) {
+	policies := make(map[string]MovePolicy)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid alien policy %q, expecting <alien>=<policy>", pair)
+		}
+		policy, err := ParsePolicy(pair[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		policies[strings.TrimSpace(pair[:i])] = policy
+	}
+	return policies, nil
+}
@@ -0,0 +1,161 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/hatricker/alieninvasion/generators"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMaxGen struct {
+}
+
+var fakeMaxGenerator = &fakeMaxGen{}
+
+func (fn *fakeMaxGen) GenerateNum(n int) int {
+	return n - 1
+}
+
+func TestMovePolicies(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		policy MovePolicy
+		city   string
+		gen    generators.NumGen
+		move   int
+	}{
+		{&UniformPolicy{}, testingCityNames[0], fakeZeroGenerator, east},
+		{&UniformPolicy{}, testingCityNames[0], fakeMaxGenerator, south},
+		{&RoadsPolicy{}, testingCityNames[3], fakeZeroGenerator, west},
+		{&RoadsPolicy{}, testingCityNames[3], fakeMaxGenerator, north},
+		{&LazyPolicy{Stay: 0.5, Inner: &RoadsPolicy{}}, testingCityNames[3], fakeZeroGenerator, 0},
+		{&LazyPolicy{Stay: 0.5, Inner: &RoadsPolicy{}}, testingCityNames[3], fakeMaxGenerator, north},
+		{&LazyPolicy{Stay: 0.5}, testingCityNames[3], fakeMaxGenerator, north},
+		{&AvoidVisitedPolicy{Memory: 3}, testingCityNames[3], fakeZeroGenerator, west},
+		{&DriftPolicy{Direction: south, Bias: 0.9}, testingCityNames[0], fakeZeroGenerator, south},
+		{&DriftPolicy{Direction: south, Bias: 0.9}, testingCityNames[0], fakeMaxGenerator, south},
+		{&DriftPolicy{Direction: north, Bias: 0.9}, testingCityNames[0], fakeZeroGenerator, east},
+	}
+
+	for _, tt := range tests {
+		game := generateGame()
+		game.AlienLocations[testingAlien] = tt.city
+		assert.Equal(tt.move, tt.policy.NextMove(game, testingAlien, tt.gen))
+	}
+
+	//the uniform policy takes wormholes too
+	game := generateGame()
+	game.CityMap[testingCityNames[0]].SetNeighbor(wormhole, game.CityMap[testingCityNames[3]])
+	assert.Equal(wormhole, (&UniformPolicy{}).NextMove(game, testingAlien, fakeMaxGenerator))
+	assert.Equal(east, (&UniformPolicy{}).NextMove(game, testingAlien, fakeZeroGenerator))
+}
+
+func TestAvoidVisitedPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	policy := NewAvoidVisitedPolicy(2)
+	game.policy = policy
+
+	assert.Equal(east, policy.NextMove(game, testingAlien, fakeZeroGenerator))
+	game.MakeMove(map[string]int{testingAlien: east})
+	//the road back west leads to a city visited just before
+	assert.Equal(south, policy.NextMove(game, testingAlien, fakeZeroGenerator))
+	game.MakeMove(map[string]int{testingAlien: south})
+	assert.Equal(west, policy.NextMove(game, testingAlien, fakeZeroGenerator))
+	game.MakeMove(map[string]int{testingAlien: west})
+	//both neighbors were visited, but the oldest one is forgotten
+	assert.Equal(north, policy.NextMove(game, testingAlien, fakeZeroGenerator))
+}
+
+func TestSeekAndFleePolicies(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		policy MovePolicy
+		other  string
+		move   int
+	}{
+		{&SeekPolicy{}, testingCityNames[2], south},
+		{&SeekPolicy{}, testingCityNames[3], east},
+		{&SeekPolicy{Radius: 1}, testingCityNames[2], south},
+		{&FleePolicy{}, testingCityNames[1], south},
+		{&FleePolicy{}, testingCityNames[2], east},
+		//both roads lead closer to the other alien
+		{&FleePolicy{}, testingCityNames[3], 0},
+		//the other alien is out of sight
+		{&FleePolicy{Radius: 1}, testingCityNames[3], south},
+	}
+
+	for _, tt := range tests {
+		game := generateGame()
+		anotherAlien := generators.AlienNames[1]
+		game.AlienLocations[anotherAlien] = tt.other
+		game.CityMap[tt.other].Aliens = append(game.CityMap[tt.other].Aliens, anotherAlien)
+		assert.Equal(tt.move, tt.policy.NextMove(game, testingAlien, fakeMaxGenerator))
+	}
+
+	//aliens on the road cannot be seen
+	game := generateGame()
+	game.AlienLocations[generators.AlienNames[1]] = testingCityNames[1]
+	game.Transits = map[string]*Transit{generators.AlienNames[1]: {From: testingCityNames[1], To: testingCityNames[3], Remaining: 1}}
+	assert.Equal(south, (&SeekPolicy{}).NextMove(game, testingAlien, fakeMaxGenerator))
+
+	//allies, in the same city or not, are not hunted
+	ally, enemy := generators.AlienNames[1], generators.AlienNames[2]
+	game = generateGame(withAlien(ally, testingCityNames[0]), withAlien(enemy, testingCityNames[3]),
+		WithFaction(testingAlien, "red"), WithFaction(ally, "red"))
+	assert.Equal(east, (&SeekPolicy{}).NextMove(game, testingAlien, fakeMaxGenerator))
+	WithFaction(enemy, "red")(game)
+	assert.Equal(south, (&SeekPolicy{}).NextMove(game, testingAlien, fakeMaxGenerator))
+}
+
+func TestGameMovePolicies(t *testing.T) {
+	assert := assert.New(t)
+
+	aliens := []string{generators.AlienNames[0], generators.AlienNames[1]}
+	game := NewGame(aliens, generateCityMap(), fakeZeroGenerator,
+		WithPolicy(&LazyPolicy{Stay: 1, Inner: &UniformPolicy{}}),
+		WithAlienPolicy(aliens[1], &UniformPolicy{}))
+
+	assert.Equal(map[string]int{aliens[1]: east}, game.GenMoves())
+	assert.IsType(&UniformPolicy{}, generateGame().policyFor(testingAlien))
+}
+
+func TestParsePolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	valid := []struct {
+		spec   string
+		policy MovePolicy
+	}{
+		{"uniform", &UniformPolicy{}},
+		{"roads", &RoadsPolicy{}},
+		{"lazy:0.25", &LazyPolicy{Stay: 0.25, Inner: &RoadsPolicy{}}},
+		{"drift:north:1", &DriftPolicy{Direction: north, Bias: 1}},
+		{"avoid:3", NewAvoidVisitedPolicy(3)},
+		{"seek", &SeekPolicy{}},
+		{"seek:4", &SeekPolicy{Radius: 4}},
+		{"flee:2", &FleePolicy{Radius: 2}},
+	}
+	for _, tt := range valid {
+		policy, err := ParsePolicy(tt.spec)
+		assert.Nil(err, tt.spec)
+		assert.Equal(tt.policy, policy, tt.spec)
+	}
+
+	for _, spec := range []string{"", "roads:1", "lazy", "lazy:2", "drift:up:0.5", "drift:east", "avoid:0", "seek:-1", "flee:1:2", "teleport"} {
+		_, err := ParsePolicy(spec)
+		assert.NotNil(err, spec)
+	}
+
+	policies, err := ParseAlienPolicies("Zidane=lazy:0.5, NeFlav Yucholl=roads")
+	assert.Nil(err)
+	assert.Equal(map[string]MovePolicy{
+		"Zidane":         &LazyPolicy{Stay: 0.5, Inner: &RoadsPolicy{}},
+		"NeFlav Yucholl": &RoadsPolicy{},
+	}, policies)
+	_, err = ParseAlienPolicies("Zidane")
+	assert.NotNil(err)
+}
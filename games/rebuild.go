@@ -0,0 +1,157 @@
+package games
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/hatricker/alieninvasion/generators"
+)
+
+//Rebuild brings destroyed cities back. A destroyed city without living
+//aliens for After moves in a row is rebuilt, and each road or wormhole it
+//had when the game started comes back with the probability Chance (0-1,
+//1 when not set), as long as the city on the other end stands. A rebuilt
+//city has no damage and no defense. Rebuild is not used when After is not set
+type Rebuild struct {
+	After  int     `json:"after"`
+	Chance float64 `json:"chance"`
+}
+
+//validate checks the rebuild settings can be used
+func (r *Rebuild) validate() error {
+	if r.After < 0 {
+		return fmt.Errorf("rebuild cannot take a negative number of moves")
+	}
+	if r.Chance < 0 || r.Chance > 1 {
+		return fmt.Errorf("rebuild chance must be within 0-1")
+	}
+	if !r.enabled() && r.Chance > 0 {
+		return fmt.Errorf("rebuild settings need a number of moves")
+	}
+	return nil
+}
+
+//enabled tells whether destroyed cities are rebuilt at all
+func (r *Rebuild) enabled() bool {
+	return r.After > 0
+}
+
+//WithRebuild makes destroyed cities come back, see Rebuild
+func WithRebuild(r Rebuild) Option {
+	return func(g *Game) {
+		g.rebuilding = r
+	}
+}
+
+//rebuild counts the moves each destroyed city has been without living
+//aliens, and rebuilds the ones left alone for long enough. Cities destroyed
+//in the current move start counting at the next one
+func (g *Game) rebuild() {
+	if !g.rebuilding.enabled() || g.OriginalMap == nil {
+		return
+	}
+	if g.idle == nil {
+		g.idle = map[string]int{}
+	}
+	for _, city := range generators.SortedCityNames(g.CityMap) {
+		if !g.IsDestroyed(city) {
+			continue
+		}
+		moves, ok := g.idle[city]
+		if !ok || len(g.livingAliensIn(city)) > 0 {
+			g.idle[city] = 0
+			continue
+		}
+		if moves++; moves < g.rebuilding.After {
+			g.idle[city] = moves
+			continue
+		}
+		delete(g.idle, city)
+		g.rebuildCity(city)
+	}
+}
+
+//rebuildCity brings the city back, along with the roads and wormholes it
+//had with cities left standing. A two-way road comes back both ways
+func (g *Game) rebuildCity(city string) {
+	gen := g.stream("rebuild")
+	share := g.rebuilding.Chance
+	if share == 0 {
+		share = 1
+	}
+	original := g.OriginalMap[city]
+	var roads []string
+	for _, direction := range original.Links() {
+		to := original.Neighbor(direction).Name
+		back := original.Back(direction)
+		if g.IsDestroyed(to) || !g.canRestore(city, direction) ||
+			(back != 0 && !g.canRestore(to, back)) || !chance(gen, share) {
+			continue
+		}
+		g.restoreLink(city, direction)
+		if back != 0 {
+			g.restoreLink(to, back)
+		}
+		roads = append(roads, to)
+	}
+	//one-way roads into the city are only known by the city they start from
+	for _, from := range generators.SortedCityNames(g.OriginalMap) {
+		node := g.OriginalMap[from]
+		for _, direction := range node.Links() {
+			if node.Neighbor(direction) != original || node.Back(direction) != 0 ||
+				g.IsDestroyed(from) || !g.canRestore(from, direction) || !chance(gen, share) {
+				continue
+			}
+			g.restoreLink(from, direction)
+			roads = append(roads, from)
+		}
+	}
+	g.CityMap[city].Damage = 0
+	g.CityMap[city].Defense = 0
+	delete(g.fires, city)
+	delete(g.Destroyed, city)
+	log.Printf("!!!!!!City %s has been rebuilt, roads to: %s !!!!!!", city, strings.Join(roads, " "))
+	g.record(CityRebuilt, []string{city}, nil, fmt.Sprintf("%d roads back", len(roads)))
+}
+
+//canRestore tells whether the direction the city had a link in when the
+//game started is still free, new roads may have been built there since
+func (g *Game) canRestore(city string, direction int) bool {
+	return g.CityMap[city].Neighbor(direction) == nil
+}
+
+//restoreLink puts back the road or wormhole leaving the city in the given
+//direction as it was when the game started
+func (g *Game) restoreLink(city string, direction int) {
+	original := g.OriginalMap[city]
+	node := g.CityMap[city]
+	node.SetNeighbor(direction, g.CityMap[original.Neighbor(direction).Name])
+	node.SetCost(direction, original.Cost(direction))
+	if original.IsOneWay(direction) {
+		node.OneWay |= direction
+	}
+	g.mapChanged()
+}
+
+//ParseRebuild parses comma separated rebuild settings made of
+//"after=<moves>" and "chance=<0-1>", e.g. "after=20,chance=0.5"
+func ParseRebuild(spec string) (Rebuild, error) {
+	var r Rebuild
+	err := parseSettings(spec, "rebuild", nil, func(key, value string) (err error) {
+		switch key {
+		case "after":
+			r.After, err = strconv.Atoi(value)
+		case "chance":
+			r.Chance, err = strconv.ParseFloat(value, 64)
+		default:
+			err = errUnknownSetting
+		}
+		return err
+	})
+	if err != nil {
+		return r, err
+	}
+	return r, r.validate()
+}
@@ -0,0 +1,95 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/hatricker/alieninvasion/generators"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebuild(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame(WithRebuild(Rebuild{After: 2}), withoutAlien(testingAlien), withDestroyed(testingCityNames[0]))
+	for i := 0; i < 2; i++ {
+		game.rebuild()
+		assert.True(game.IsDestroyed(testingCityNames[0]))
+	}
+	game.rebuild()
+	assert.False(game.IsDestroyed(testingCityNames[0]))
+	from := game.CityMap[testingCityNames[0]]
+	assert.Equal(game.CityMap[testingCityNames[1]], from.East)
+	assert.Equal(from, game.CityMap[testingCityNames[1]].West)
+	assert.Equal(game.CityMap[testingCityNames[2]], from.South)
+	assert.Equal(from, game.CityMap[testingCityNames[2]].North)
+	assert.Equal(Event{
+		Kind:    CityRebuilt,
+		Cities:  []string{testingCityNames[0]},
+		Aliens:  []string{},
+		Outcome: "2 roads back",
+	}, game.Events[0])
+	assert.Empty(game.idle)
+
+	//living aliens keep a city from being rebuilt
+	game = generateGame(WithRebuild(Rebuild{After: 1}), withoutAlien(testingAlien), withDestroyed(testingCityNames[0]))
+	game.AlienLocations[testingAlien] = testingCityNames[0]
+	for i := 0; i < 3; i++ {
+		game.rebuild()
+	}
+	assert.True(game.IsDestroyed(testingCityNames[0]))
+}
+
+func TestRebuildRoads(t *testing.T) {
+	assert := assert.New(t)
+
+	//roads to destroyed cities stay cut
+	game := generateGame(WithRebuild(Rebuild{After: 1}), withoutAlien(testingAlien), withDestroyed(testingCityNames[0], testingCityNames[1]))
+	game.rebuildCity(testingCityNames[0])
+	from := game.CityMap[testingCityNames[0]]
+	assert.Nil(from.East)
+	assert.Equal([]int{south}, from.Links())
+
+	//roads come back with the chance
+	game = generateGame(WithRebuild(Rebuild{After: 1, Chance: 0.5}), withoutAlien(testingAlien), withDestroyed(testingCityNames[0]))
+	game.randGen = fakeMaxGenerator
+	game.rebuildCity(testingCityNames[0])
+	assert.False(game.IsDestroyed(testingCityNames[0]))
+	assert.Empty(game.CityMap[testingCityNames[0]].Links())
+
+	//one-way roads into the city come back one way
+	game = generateGame()
+	game.CityMap[testingCityNames[0]].South = nil
+	game.CityMap[testingCityNames[2]].OneWay |= north
+	game.CityMap[testingCityNames[2]].SetCost(north, 2)
+	game.OriginalMap = generators.CloneCityMap(game.CityMap)
+	game.DestroyCity(testingCityNames[0])
+	game.rebuildCity(testingCityNames[0])
+	to := game.CityMap[testingCityNames[2]]
+	assert.Equal(game.CityMap[testingCityNames[0]], to.North)
+	assert.True(to.IsOneWay(north))
+	assert.Equal(2, to.Cost(north))
+	assert.Nil(game.CityMap[testingCityNames[0]].South)
+}
+
+func TestParseRebuild(t *testing.T) {
+	assert := assert.New(t)
+
+	valid := []struct {
+		spec    string
+		rebuild Rebuild
+	}{
+		{"", Rebuild{}},
+		{"after=10", Rebuild{After: 10}},
+		{"after=5, chance=0.5", Rebuild{After: 5, Chance: 0.5}},
+	}
+	for _, tt := range valid {
+		r, err := ParseRebuild(tt.spec)
+		assert.Nil(err, tt.spec)
+		assert.Equal(tt.rebuild, r, tt.spec)
+	}
+
+	for _, spec := range []string{"after", "after=x", "after=-1", "chance=0.5", "after=1,chance=2", "soon=1"} {
+		_, err := ParseRebuild(spec)
+		assert.NotNil(err, spec)
+	}
+}
@@ -0,0 +1,152 @@
+package games
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/hatricker/alieninvasion/generators"
+)
+
+//defaultCooldown is the number of moves an alien waits between breeding in
+//undamaged cities when Reproduction does not set it
+const defaultCooldown = 5
+
+//Reproduction lets aliens spawn offspring into the cities next to them.
+//An alien breeds once it has survived Age moves since it landed or last
+//bred, or with Undamaged set, while it is in a city which has taken no
+//damage, at most once every Cooldown moves (5 when not set). The offspring
+//lands in a random city the alien has a road to, joins its parent's faction
+//and moves with its parent's policy
+//Cap is the number of living aliens at which breeding stops, the number of
+//cities when not set. Aliens do not breed when neither Age nor Undamaged is
+//set
+type Reproduction struct {
+	Age       int  `json:"age"`
+	Undamaged bool `json:"undamaged"`
+	Cooldown  int  `json:"cooldown"`
+	Cap       int  `json:"cap"`
+}
+
+//validate checks the reproduction rule can be used
+func (r *Reproduction) validate() error {
+	if r.Age < 0 || r.Cooldown < 0 || r.Cap < 0 {
+		return fmt.Errorf("reproduction age, cooldown and cap cannot be negative")
+	}
+	if !r.enabled() && r.Cap > 0 {
+		return fmt.Errorf("reproduction settings need an age or undamaged")
+	}
+	if !r.Undamaged && r.Cooldown > 0 {
+		return fmt.Errorf("reproduction cooldown needs undamaged")
+	}
+	return nil
+}
+
+//enabled tells whether aliens breed at all
+func (r *Reproduction) enabled() bool {
+	return r.Age > 0 || r.Undamaged
+}
+
+//cooldown returns the number of moves an alien waits between breeding in
+//undamaged cities
+func (r *Reproduction) cooldown() int {
+	if r.Cooldown == 0 {
+		return defaultCooldown
+	}
+	return r.Cooldown
+}
+
+//WithReproduction lets aliens breed, see Reproduction
+func WithReproduction(r Reproduction) Option {
+	return func(g *Game) {
+		g.reproduction = r
+	}
+}
+
+//markBirth notes the alien joined the game, or bred, at the current move
+func (g *Game) markBirth(alien string) {
+	if g.births == nil {
+		g.births = make(map[string]int)
+	}
+	g.births[alien] = g.turn
+}
+
+//canBreed tells whether the alien is ready to breed at the current move
+func (g *Game) canBreed(alien string) bool {
+	r := g.reproduction
+	since := g.turn - g.births[alien]
+	if r.Age > 0 && since >= r.Age {
+		return true
+	}
+	city := g.AlienLocations[alien]
+	return r.Undamaged && since >= r.cooldown() && g.CityMap[city].Damage == 0 && !g.IsDestroyed(city)
+}
+
+//breed lets the aliens ready to breed spawn an offspring each, until the
+//population cap is reached
+func (g *Game) breed() {
+	if !g.reproduction.enabled() {
+		return
+	}
+	limit := g.reproduction.Cap
+	if limit == 0 {
+		limit = len(g.CityMap)
+	}
+	for _, alien := range g.alienNames() {
+		if len(g.AlienLocations) >= limit {
+			return
+		}
+		if _, ok := g.Transits[alien]; ok || !g.canBreed(alien) {
+			continue
+		}
+		node := g.CityMap[g.AlienLocations[alien]]
+		roads := roadsOf(node)
+		if len(roads) == 0 {
+			continue
+		}
+		gen := g.alienStream("reproduction", alien)
+		next := node.Neighbor(pickRoad(roads, gen))
+		child := generators.GenerateProceduralNames(arrayGen(gen), 1, g.knownAliens())[0]
+
+		next.Aliens = append(next.Aliens, child)
+		g.AlienLocations[child] = next.Name
+		g.spawnOrder = append(g.spawnOrder, child)
+		if faction, ok := g.Factions[alien]; ok {
+			WithFaction(child, faction)(g)
+		}
+		if policy, ok := g.alienPolicies[alien]; ok {
+			WithAlienPolicy(child, policy)(g)
+		}
+		g.assignAttributes([]string{child})
+		g.markBirth(alien)
+		g.markBirth(child)
+		log.Printf("Alien [%s] in <%s> spawned [%s] into <%s>", alien, node.Name, child, next.Name)
+		g.record(Offspring, []string{node.Name, next.Name}, []string{alien, child}, "")
+	}
+}
+
+//ParseReproduction parses a comma separated reproduction rule made of
+//"age=<moves>", "undamaged", "cooldown=<moves>" and "cap=<aliens>", e.g.
+//"age=10,cap=50"
+func ParseReproduction(spec string) (Reproduction, error) {
+	var r Reproduction
+	err := parseSettings(spec, "reproduction", []string{"undamaged"}, func(key, value string) (err error) {
+		switch key {
+		case "undamaged":
+			r.Undamaged = true
+		case "age":
+			r.Age, err = strconv.Atoi(value)
+		case "cooldown":
+			r.Cooldown, err = strconv.Atoi(value)
+		case "cap":
+			r.Cap, err = strconv.Atoi(value)
+		default:
+			err = errUnknownSetting
+		}
+		return err
+	})
+	if err != nil {
+		return r, err
+	}
+	return r, r.validate()
+}
@@ -0,0 +1,122 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreedByAge(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	WithReproduction(Reproduction{Age: 2})(game)
+	WithFaction(testingAlien, "red")(game)
+	WithAlienPolicy(testingAlien, &RoadsPolicy{})(game)
+
+	game.turn = 1
+	game.breed()
+	assert.Equal(1, len(game.AlienLocations))
+
+	game.turn = 2
+	game.breed()
+	assert.Equal(2, len(game.AlienLocations))
+	births := game.EventsOf(Offspring)
+	assert.Equal(1, len(births))
+	assert.Equal([]string{testingCityNames[0], testingCityNames[1]}, births[0].Cities)
+	child := births[0].Aliens[1]
+	assert.Equal(testingCityNames[1], game.AlienLocations[child])
+	assert.Equal([]string{child}, game.livingAliensIn(testingCityNames[1]))
+	assert.Equal("red", game.Factions[child])
+	assert.Equal(&RoadsPolicy{}, game.policyFor(child))
+	assert.NotNil(game.Aliens[child])
+
+	game.turn = 3
+	game.breed()
+	assert.Equal(2, len(game.AlienLocations))
+
+	//both aliens breed, reaching the cap of one alien per city
+	game.turn = 4
+	game.breed()
+	assert.Equal(4, len(game.AlienLocations))
+	game.turn = 6
+	game.breed()
+	assert.Equal(4, len(game.AlienLocations))
+}
+
+func TestBreedInUndamagedCity(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, damage := range []int{0, 5} {
+		game := generateGame()
+		WithReproduction(Reproduction{Undamaged: true, Cooldown: 2, Cap: 3})(game)
+		game.CityMap[testingCityNames[0]].Damage = damage
+
+		game.turn = 2
+		game.breed()
+		if damage > 0 {
+			assert.Equal(1, len(game.AlienLocations))
+			continue
+		}
+		assert.Equal(2, len(game.AlienLocations))
+
+		//neither the parent nor its offspring breeds again before its cooldown
+		game.turn = 3
+		game.breed()
+		assert.Equal(2, len(game.AlienLocations))
+		game.turn = 4
+		game.breed()
+		assert.Equal(3, len(game.AlienLocations))
+	}
+
+	//an alien old enough breeds even in a damaged city
+	game := generateGame()
+	WithReproduction(Reproduction{Age: 3, Undamaged: true})(game)
+	game.CityMap[testingCityNames[0]].Damage = 5
+	game.turn = 2
+	game.breed()
+	assert.Equal(1, len(game.AlienLocations))
+	game.turn = 3
+	game.breed()
+	assert.Equal(2, len(game.AlienLocations))
+}
+
+func TestReproductionCooldown(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(defaultCooldown, (&Reproduction{Undamaged: true}).cooldown())
+	assert.Equal(2, (&Reproduction{Undamaged: true, Cooldown: 2}).cooldown())
+}
+
+func TestNoBreedingByDefault(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	for turn := 1; turn < 4; turn++ {
+		game.turn = turn
+		game.breed()
+	}
+	assert.Equal(1, len(game.AlienLocations))
+	assert.Empty(game.Events)
+}
+
+func TestParseReproduction(t *testing.T) {
+	assert := assert.New(t)
+
+	r, err := ParseReproduction("age=10, undamaged,cooldown=3,cap=50")
+	assert.Nil(err)
+	assert.Equal(Reproduction{Age: 10, Undamaged: true, Cooldown: 3, Cap: 50}, r)
+
+	r, err = ParseReproduction("undamaged")
+	assert.Nil(err)
+	assert.True(r.enabled())
+
+	r, err = ParseReproduction("")
+	assert.Nil(err)
+	assert.False(r.enabled())
+
+	for _, spec := range []string{"age", "age=x", "age=-1", "cap=-1", "size=3", "age=1=2", "cap=5", "age=2,undamaged=1", "age=2,cooldown=3", "undamaged,cooldown=-1"} {
+		_, err := ParseReproduction(spec)
+		assert.NotNil(err, spec)
+	}
+}
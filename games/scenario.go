@@ -0,0 +1,125 @@
+package games
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+//Scenario describes the set up of a game which does not fit on the command
+//line. It is read from JSON, e.g.
+//	{"factions": {"red": ["Zidane", "Salah"], "blue": ["Degir"]},
+//	 "waves": [{"count": 5, "every": 50, "placement": "edge"}],
+//	 "reproduction": {"age": 10, "cap": 50},
+//	 "energy": {"capacity": 20, "recharge": 1},
+//	 "mapEvents": [{"kind": "meteor", "every": 50}],
+//	 "fire": {"chance": 0.2, "burn": 3},
+//	 "rebuild": {"after": 20, "chance": 0.5}}
+//Factions lists the aliens of each faction
+//Waves schedules reinforcements, see Wave
+//Reproduction lets the aliens breed when set, see Reproduction
+//Energy limits how far the aliens go when set, see Energy
+//MapEvents change the map during the game, see MapEvent
+//Fire makes the destruction of cities spread when set, see Fire
+//Rebuild brings destroyed cities back when set, see Rebuild
+type Scenario struct {
+	Factions     map[string][]string `json:"factions"`
+	Waves        []Wave              `json:"waves"`
+	Reproduction *Reproduction       `json:"reproduction"`
+	Energy       *Energy             `json:"energy"`
+	MapEvents    []MapEvent          `json:"mapEvents"`
+	Fire         *Fire               `json:"fire"`
+	Rebuild      *Rebuild            `json:"rebuild"`
+}
+
+//LoadScenario reads a scenario in JSON from r
+func LoadScenario(r io.Reader) (*Scenario, error) {
+	var s Scenario
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid scenario, %v", err)
+	}
+	seen := make(map[string]string)
+	for faction, aliens := range s.Factions {
+		if faction == "" {
+			return nil, fmt.Errorf("invalid scenario, empty faction name")
+		}
+		for _, alien := range aliens {
+			if other, ok := seen[alien]; ok && other != faction {
+				return nil, fmt.Errorf("invalid scenario, alien %s is in factions %s and %s", alien, other, faction)
+			}
+			seen[alien] = faction
+		}
+	}
+	for _, v := range s.validators() {
+		if err := v.validate(); err != nil {
+			return nil, fmt.Errorf("invalid scenario, %v", err)
+		}
+	}
+	return &s, nil
+}
+
+//validator is a part of a scenario which checks itself
+type validator interface {
+	validate() error
+}
+
+//validators returns the parts of the scenario to check
+func (s *Scenario) validators() []validator {
+	var validators []validator
+	for i := range s.Waves {
+		validators = append(validators, &s.Waves[i])
+	}
+	if s.Reproduction != nil {
+		validators = append(validators, s.Reproduction)
+	}
+	if s.Energy != nil {
+		validators = append(validators, s.Energy)
+	}
+	for i := range s.MapEvents {
+		validators = append(validators, &s.MapEvents[i])
+	}
+	if s.Fire != nil {
+		validators = append(validators, s.Fire)
+	}
+	if s.Rebuild != nil {
+		validators = append(validators, s.Rebuild)
+	}
+	return validators
+}
+
+//Options returns the game options setting up the scenario
+func (s *Scenario) Options() []Option {
+	var opts []Option
+	factions := make([]string, 0, len(s.Factions))
+	for faction := range s.Factions {
+		factions = append(factions, faction)
+	}
+	sort.Strings(factions)
+	for _, faction := range factions {
+		for _, alien := range s.Factions[faction] {
+			opts = append(opts, WithFaction(alien, faction))
+		}
+	}
+	if len(s.Waves) > 0 {
+		opts = append(opts, WithWaves(s.Waves...))
+	}
+	if s.Reproduction != nil {
+		opts = append(opts, WithReproduction(*s.Reproduction))
+	}
+	if s.Energy != nil {
+		opts = append(opts, WithEnergy(*s.Energy))
+	}
+	if len(s.MapEvents) > 0 {
+		opts = append(opts, WithMapEvents(s.MapEvents...))
+	}
+	if s.Fire != nil {
+		opts = append(opts, WithFire(*s.Fire))
+	}
+	if s.Rebuild != nil {
+		opts = append(opts, WithRebuild(*s.Rebuild))
+	}
+	return opts
+}
@@ -0,0 +1,56 @@
+package games
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadScenario(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := LoadScenario(strings.NewReader(`{"factions": {"red": ["Zidane", "Salah"], "blue": ["Degir"]},
+		"waves": [{"count": 5, "every": 50, "placement": "edge", "faction": "red"}],
+		"reproduction": {"age": 10, "cap": 50},
+		"energy": {"capacity": 20, "recharge": 1},
+		"mapEvents": [{"kind": "meteor", "every": 50}],
+		"fire": {"chance": 0.2},
+		"rebuild": {"after": 20}}`))
+	assert.Nil(err)
+	assert.Equal(map[string][]string{"red": {"Zidane", "Salah"}, "blue": {"Degir"}}, s.Factions)
+	assert.Equal([]Wave{{Count: 5, Every: 50, Placement: "edge", Faction: "red"}}, s.Waves)
+
+	game := &Game{}
+	for _, opt := range s.Options() {
+		opt(game)
+	}
+	assert.Equal(map[string]string{"Zidane": "red", "Salah": "red", "Degir": "blue"}, game.Factions)
+	assert.Equal(s.Waves, game.waves)
+	assert.Equal(Reproduction{Age: 10, Cap: 50}, game.reproduction)
+	assert.Equal(Energy{Capacity: 20, Recharge: 1}, game.energy)
+	assert.Equal([]MapEvent{{Kind: "meteor", Every: 50}}, game.mapEvents)
+	assert.Equal(Fire{Chance: 0.2}, game.fire)
+	assert.Equal(Rebuild{After: 20}, game.rebuilding)
+
+	for _, input := range []string{
+		``,
+		`{"factions": []}`,
+		`{"teams": {}}`,
+		`{"factions": {"": ["Zidane"]}}`,
+		`{"factions": {"red": ["Zidane"], "blue": ["Zidane"]}}`,
+		`{"waves": [{"count": 0}]}`,
+		`{"waves": [{"count": 1, "placement": "middle"}]}`,
+		`{"reproduction": {"age": -1}}`,
+		`{"energy": {"capacity": -1}}`,
+		`{"energy": {"recharge": 1}}`,
+		`{"mapEvents": [{"kind": "flood", "every": 1}]}`,
+		`{"fire": {"chance": 2}}`,
+		`{"fire": {"burn": 2}}`,
+		`{"rebuild": {"after": -1}}`,
+		`{"rebuild": {"chance": 0.5}}`,
+	} {
+		_, err := LoadScenario(strings.NewReader(input))
+		assert.NotNil(err, input)
+	}
+}
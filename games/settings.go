@@ -0,0 +1,37 @@
+package games
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//errUnknownSetting is returned by the setters of parseSettings for a key
+//they do not know
+var errUnknownSetting = errors.New("unknown setting")
+
+//parseSettings parses comma separated settings of the given kind, made of
+//"<key>=<value>" pairs and of flags standing alone. It hands each of them to
+//set, flags with an empty value. A flag cannot be given a value
+func parseSettings(spec, kind string, flags []string, set func(key, value string) error) error {
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value := item, ""
+		if !contains(flags, item) {
+			parts := strings.Split(item, "=")
+			if len(parts) != 2 || contains(flags, parts[0]) {
+				return fmt.Errorf("invalid %s setting %q", kind, item)
+			}
+			key, value = parts[0], parts[1]
+		}
+		if err := set(key, value); err == errUnknownSetting {
+			return fmt.Errorf("unknown %s setting %q", kind, item)
+		} else if err != nil {
+			return fmt.Errorf("invalid %s setting %q, %v", kind, item, err)
+		}
+	}
+	return nil
+}
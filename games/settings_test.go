@@ -0,0 +1,45 @@
+package games
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSettings(t *testing.T) {
+	assert := assert.New(t)
+
+	settings := make(map[string]string)
+	set := func(key, value string) error {
+		if key == "bad" {
+			return errUnknownSetting
+		}
+		if key == "n" {
+			if _, err := strconv.Atoi(value); err != nil {
+				return err
+			}
+		}
+		settings[key] = value
+		return nil
+	}
+	assert.Nil(parseSettings(" n=1, flag,,x=y ", "test", []string{"flag"}, set))
+	assert.Equal(map[string]string{"n": "1", "flag": "", "x": "y"}, settings)
+
+	tests := []struct {
+		spec string
+		err  string
+	}{
+		{"n", `invalid test setting "n"`},
+		{"n=1=2", `invalid test setting "n=1=2"`},
+		{"flag=1", `invalid test setting "flag=1"`},
+		{"bad=1", `unknown test setting "bad=1"`},
+		{"n=x", `invalid test setting "n=x", strconv.Atoi: parsing "x": invalid syntax`},
+	}
+	for _, tt := range tests {
+		err := parseSettings(tt.spec, "test", []string{"flag"}, set)
+		if assert.NotNil(err, tt.spec) {
+			assert.Equal(tt.err, err.Error(), tt.spec)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package games
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hatricker/alieninvasion/generators"
+)
+
+//Wave schedules reinforcements: Count new aliens land every Every moves,
+//the first time at move Start (Every when not set). A wave with Every not
+//set lands once. Times limits the number of landings, 0 means no limit
+//Placement is "random" (the default) for any city left standing, or "edge"
+//for cities with fewer than four roads. New aliens join Faction when set
+type Wave struct {
+	Count     int    `json:"count"`
+	Every     int    `json:"every"`
+	Start     int    `json:"start"`
+	Times     int    `json:"times"`
+	Placement string `json:"placement"`
+	Faction   string `json:"faction"`
+}
+
+//validate checks the wave can be scheduled
+func (w *Wave) validate() error {
+	switch {
+	case w.Count <= 0:
+		return fmt.Errorf("wave needs a positive number of aliens")
+	case w.Every < 0 || w.Start < 0 || w.Times < 0:
+		return fmt.Errorf("wave schedule cannot be negative")
+	case w.Placement != "" && w.Placement != "random" && w.Placement != "edge":
+		return fmt.Errorf("unknown wave placement %q", w.Placement)
+	}
+	return nil
+}
+
+//first returns the move the wave lands at for the first time
+func (w *Wave) first() int {
+	if w.Start == 0 {
+		return w.Every
+	}
+	return w.Start
+}
+
+//landsAt tells whether the wave lands at the move
+func (w *Wave) landsAt(turn int) bool {
+	since := turn - w.first()
+	switch {
+	case since < 0:
+		return false
+	case w.Every == 0:
+		return since == 0
+	}
+	return since%w.Every == 0 && (w.Times == 0 || since/w.Every < w.Times)
+}
+
+//landsFrom tells whether the wave lands at the move or any move after it
+func (w *Wave) landsFrom(turn int) bool {
+	since := turn - w.first()
+	switch {
+	case since <= 0:
+		return true
+	case w.Every == 0:
+		return false
+	}
+	return w.Times == 0 || (since+w.Every-1)/w.Every < w.Times
+}
+
+//WithWaves schedules reinforcement waves
+func WithWaves(waves ...Wave) Option {
+	return func(g *Game) {
+		g.waves = append(g.waves, waves...)
+	}
+}
+
+//wavesAhead tells whether any wave is still to land from the move on
+func (g *Game) wavesAhead(turn int) bool {
+	for i := range g.waves {
+		if g.waves[i].landsFrom(turn) {
+			return true
+		}
+	}
+	return false
+}
+
+//landWaves puts the aliens of the waves landing at the current move
+//on the map, naming them with procedurally generated names. Like
+//spreadAliensOntoMap, it puts each alien in a different city without
+//living aliens, and only stacks them once there are no such cities left
+func (g *Game) landWaves() {
+	for i := range g.waves {
+		wave := &g.waves[i]
+		if !wave.landsAt(g.turn) {
+			continue
+		}
+		cities := g.landingCities(wave.Placement)
+		if len(cities) == 0 {
+			log.Printf("No city left for a wave of %d aliens to land in", wave.Count)
+			continue
+		}
+		gen := g.stream("waves")
+		aliens := generators.GenerateProceduralNames(arrayGen(gen), wave.Count, g.knownAliens())
+		free := g.freeCities(cities)
+		landed := make(map[string]bool)
+		for _, alien := range aliens {
+			var city string
+			if len(free) > 0 {
+				num := gen.GenerateNum(len(free))
+				city = free[num]
+				free = append(free[:num], free[num+1:]...)
+			} else {
+				city = cities[gen.GenerateNum(len(cities))]
+			}
+			node := g.CityMap[city]
+			node.Aliens = append(node.Aliens, alien)
+			g.AlienLocations[alien] = node.Name
+			g.spawnOrder = append(g.spawnOrder, alien)
+			g.markBirth(alien)
+			if wave.Faction != "" {
+				WithFaction(alien, wave.Faction)(g)
+			}
+			landed[node.Name] = true
+		}
+		g.assignAttributes(aliens)
+		names := make([]string, 0, len(landed))
+		for city := range landed {
+			names = append(names, city)
+		}
+		sort.Strings(names)
+		log.Printf("!!!!!!Reinforcements %s landed in %s !!!!!!", strings.Join(aliens, " "), strings.Join(names, " "))
+		g.record(Reinforcement, names, aliens, "")
+	}
+}
+
+//landingCities returns the cities left standing new aliens can land in,
+//for "edge" only the ones with fewer than four roads
+func (g *Game) landingCities(placement string) []string {
+	var cities []string
+	for _, city := range generators.SortedCityNames(g.CityMap) {
+		if g.IsDestroyed(city) {
+			continue
+		}
+		if placement == "edge" && compassRoads(g.CityMap[city]) == len(generators.DirectionBitMap) {
+			continue
+		}
+		cities = append(cities, city)
+	}
+	return cities
+}
+
+//freeCities returns the cities without living aliens
+func (g *Game) freeCities(cities []string) []string {
+	var free []string
+	for _, city := range cities {
+		if len(g.livingAliensIn(city)) == 0 {
+			free = append(free, city)
+		}
+	}
+	return free
+}
+
+//compassRoads returns the number of roads leaving the city, wormholes
+//do not count
+func compassRoads(node *generators.CityNode) int {
+	roads := 0
+	for _, direction := range generators.DirectionBitMap {
+		if node.Neighbor(direction) != nil {
+			roads++
+		}
+	}
+	return roads
+}
+
+//knownAliens returns the names of all aliens which joined the game
+func (g *Game) knownAliens() []string {
+	aliens := append([]string{}, g.spawnOrder...)
+	for alien := range g.AlienLocations {
+		aliens = append(aliens, alien)
+	}
+	return aliens
+}
+
+//arrayGen returns gen as a NumArrayGen, shuffling with gen when it is
+//not one already
+func arrayGen(gen generators.NumGen) generators.NumArrayGen {
+	if ag, ok := gen.(generators.NumArrayGen); ok {
+		return ag
+	}
+	return shuffler{gen}
+}
+
+//shuffler generates permutations from a NumGen
+type shuffler struct {
+	generators.NumGen
+}
+
+//GenerateNums implements NumArrayGen interface
+func (s shuffler) GenerateNums(num int) []int {
+	nums := make([]int, num)
+	for i := range nums {
+		nums[i] = i
+	}
+	for i := num - 1; i > 0; i-- {
+		j := s.GenerateNum(i + 1)
+		nums[i], nums[j] = nums[j], nums[i]
+	}
+	return nums
+}
+
+//ParseWaves parses a comma separated list of waves written as
+//<count>:<every>[:<placement>], e.g. "5:50:edge,2:10"
+func ParseWaves(spec string) ([]Wave, error) {
+	var waves []Wave
+	for _, s := range strings.Split(spec, ",") {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		parts := strings.Split(strings.TrimSpace(s), ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid wave %q, expecting <count>:<every>[:<placement>]", s)
+		}
+		count, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid wave %q, %v", s, err)
+		}
+		every, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid wave %q, %v", s, err)
+		}
+		wave := Wave{Count: count, Every: every}
+		if len(parts) == 3 {
+			wave.Placement = parts[2]
+		}
+		if err := wave.validate(); err != nil {
+			return nil, err
+		}
+		waves = append(waves, wave)
+	}
+	return waves, nil
+}
@@ -0,0 +1,97 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaveSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		wave  Wave
+		lands []int
+	}{
+		{Wave{Count: 1}, []int{0}},
+		{Wave{Count: 1, Start: 3}, []int{3}},
+		{Wave{Count: 1, Every: 3}, []int{3, 6, 9}},
+		{Wave{Count: 1, Every: 3, Start: 1}, []int{1, 4, 7, 10}},
+		{Wave{Count: 1, Every: 4, Times: 2}, []int{4, 8}},
+	}
+
+	for _, tt := range tests {
+		var lands []int
+		for turn := 0; turn <= 10; turn++ {
+			if tt.wave.landsAt(turn) {
+				lands = append(lands, turn)
+			}
+			last := tt.lands[len(tt.lands)-1]
+			assert.Equal(turn <= last || tt.wave.Times == 0 && tt.wave.Every > 0, tt.wave.landsFrom(turn), tt.wave, turn)
+		}
+		assert.Equal(tt.lands, lands, tt.wave)
+	}
+}
+
+func TestLandWaves(t *testing.T) {
+	assert := assert.New(t)
+
+	game := generateGame()
+	WithWaves(Wave{Count: 2, Every: 2, Faction: "red"}, Wave{Count: 1, Start: 3, Placement: "edge"})(game)
+	game.DestroyCity(testingCityNames[0])
+
+	game.turn = 1
+	game.landWaves()
+	assert.Equal(1, len(game.AlienLocations))
+
+	game.turn = 2
+	game.landWaves()
+	assert.Equal(3, len(game.AlienLocations))
+	landed := game.EventsOf(Reinforcement)
+	assert.Equal(1, len(landed))
+	//the aliens of a wave land in different cities
+	assert.Equal([]string{testingCityNames[2], testingCityNames[1]}, landed[0].Cities)
+	assert.Equal(2, len(landed[0].Aliens))
+	for i, alien := range landed[0].Aliens {
+		assert.NotEqual(testingAlien, alien)
+		assert.Equal(landed[0].Cities[i], game.AlienLocations[alien])
+		assert.Equal([]string{alien}, game.livingAliensIn(landed[0].Cities[i]))
+		assert.Equal("red", game.Factions[alien])
+		assert.NotNil(game.Aliens[alien])
+	}
+
+	game.turn = 3
+	game.landWaves()
+	assert.Equal(testingCityNames[3], game.EventsOf(Reinforcement)[1].Cities[0])
+
+	//with no free city left, the aliens share cities
+	game.turn = 4
+	game.landWaves()
+	assert.Equal(6, len(game.AlienLocations))
+	assert.Equal(3, len(game.EventsOf(Reinforcement)))
+	assert.Equal(3, len(game.livingAliensIn(testingCityNames[2])))
+}
+
+func TestStartGameWaitsForWaves(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(nil, generateCityMap(), fakeZeroGenerator, WithWaves(Wave{Count: 1, Start: 2}))
+	game.StartGame(5)
+	landed := game.EventsOf(Reinforcement)
+	assert.Equal(1, len(landed))
+	assert.Equal(2, landed[0].Turn)
+	assert.Equal(1, len(game.AlienLocations))
+}
+
+func TestParseWaves(t *testing.T) {
+	assert := assert.New(t)
+
+	waves, err := ParseWaves("5:50:edge, 2:10")
+	assert.Nil(err)
+	assert.Equal([]Wave{{Count: 5, Every: 50, Placement: "edge"}, {Count: 2, Every: 10}}, waves)
+
+	for _, spec := range []string{"5", "5:x", "x:5", "0:5", "5:-1", "5:5:middle", "5:5:edge:1"} {
+		_, err := ParseWaves(spec)
+		assert.NotNil(err, spec)
+	}
+}
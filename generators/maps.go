@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math/bits"
 	"math/rand"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -30,10 +33,12 @@ var (
 		"Olgsivoor", "Ghuyot", "Kragitur", "Zumbal", "Zidane",
 		"Luvendav", "Tamer", "Ruavu", "Ofnatsuza", "Cleayomaar"}
 
-	//ErrReqTooLarge is returned when the requsted number of aliens or cities is beyond the size of predefined list
+	//ErrReqTooLarge is returned when more aliens are requested than there are cities to place them
 	ErrReqTooLarge = fmt.Errorf("requested size is too large")
 	//ErrInvalidInput is returned when the input is invalid
 	ErrInvalidInput = fmt.Errorf("invalid input")
+	//ErrInvalidMap is returned when the roads of a city map are inconsistent
+	ErrInvalidMap = fmt.Errorf("invalid city map")
 
 	East  = 1
 	West  = 2
@@ -41,6 +46,15 @@ var (
 	South = 8
 	//DirectionBitMap is a map of 4 directions
 	DirectionBitMap = []int{East, West, North, South}
+	//DirectionNames holds the names used in map files, in the same order as DirectionBitMap
+	DirectionNames = []string{"east", "west", "north", "south"}
+	//Wormhole is the direction of the first wormhole of a city, further
+	//wormholes go in the next directions, Wormhole<<1 and so on
+	Wormhole = 16
+	//MaxWormholes is the number of wormholes a city can have
+	MaxWormholes = 8
+	//WormholeName is the name of wormholes used in map files
+	WormholeName = "wormhole"
 
 	RandNumGenerator    = &RandNumGen{}
 	RandNumArrGenerator = &RandNumArrayGen{}
@@ -71,10 +85,185 @@ type RandNumArrayGen struct {
 }
 
 //CityNode defines a city node in the whole map
+//OneWay is a bitmask of the directions (see DirectionBitMap) whose outgoing
+//road can only be travelled from this city. Roads are two-way by default
+//Costs keeps the number of moves needed to travel the road in a direction,
+//roads without an entry cost one move
+//Damage is the damage the city took from fights in it
+//Defense is the number of attacks the city can still repel before it falls
+//Wormholes link the city to distant cities, not tied to a compass direction.
+//The wormhole at index i is travelled in direction Wormhole<<i, so it can be
+//one-way or cost more than a move just like a road
 type CityNode struct {
 	Name                     string
 	East, West, North, South *CityNode
+	Wormholes                []*CityNode
+	OneWay                   int
+	Costs                    map[int]int
 	Aliens                   []string
+	Damage                   int
+	Defense                  int
+}
+
+//Neighbor returns the city reached by the road in the given direction,
+//or nil when there is no such road
+func (cn *CityNode) Neighbor(direction int) *CityNode {
+	switch direction {
+	case East:
+		return cn.East
+	case West:
+		return cn.West
+	case North:
+		return cn.North
+	case South:
+		return cn.South
+	}
+	if i := wormholeIndex(direction); i >= 0 && i < len(cn.Wormholes) {
+		return cn.Wormholes[i]
+	}
+	return nil
+}
+
+//SetNeighbor sets the road leaving the city in the given direction to
+//neighbor, costing one move and not marked one-way. It only sets this side
+//of the road, the road back from neighbor is left to the caller. A nil
+//neighbor removes the road
+func (cn *CityNode) SetNeighbor(direction int, neighbor *CityNode) {
+	switch direction {
+	case East:
+		cn.East = neighbor
+	case West:
+		cn.West = neighbor
+	case North:
+		cn.North = neighbor
+	case South:
+		cn.South = neighbor
+	default:
+		i := wormholeIndex(direction)
+		if i < 0 {
+			return
+		}
+		for len(cn.Wormholes) <= i {
+			cn.Wormholes = append(cn.Wormholes, nil)
+		}
+		cn.Wormholes[i] = neighbor
+	}
+	cn.OneWay &^= direction
+	delete(cn.Costs, direction)
+}
+
+//Links returns the directions of all roads and wormholes leaving the city,
+//the roads first in the order of DirectionBitMap
+func (cn *CityNode) Links() []int {
+	links := make([]int, 0, len(DirectionBitMap)+len(cn.Wormholes))
+	for _, direction := range DirectionBitMap {
+		if cn.Neighbor(direction) != nil {
+			links = append(links, direction)
+		}
+	}
+	for i, neighbor := range cn.Wormholes {
+		if neighbor != nil {
+			links = append(links, Wormhole<<i)
+		}
+	}
+	return links
+}
+
+//Back returns the direction of the road or wormhole leading from the
+//neighbor in the given direction back to the city, 0 when there is none
+func (cn *CityNode) Back(direction int) int {
+	neighbor := cn.Neighbor(direction)
+	if neighbor == nil {
+		return 0
+	}
+	if direction < Wormhole {
+		if neighbor.Neighbor(Opposite(direction)) == cn {
+			return Opposite(direction)
+		}
+		return 0
+	}
+	for i, w := range neighbor.Wormholes {
+		if w == cn {
+			return Wormhole << i
+		}
+	}
+	return 0
+}
+
+//wormholeIndex returns the index of the wormhole travelled in the
+//direction, or -1 when the direction is not a wormhole
+func wormholeIndex(direction int) int {
+	if direction < Wormhole || direction&(direction-1) != 0 {
+		return -1
+	}
+	i := bits.TrailingZeros(uint(direction)) - bits.TrailingZeros(uint(Wormhole))
+	if i >= MaxWormholes {
+		return -1
+	}
+	return i
+}
+
+//LinkName returns the name of the direction used in map files
+func LinkName(direction int) string {
+	for i, d := range DirectionBitMap {
+		if d == direction {
+			return DirectionNames[i]
+		}
+	}
+	if wormholeIndex(direction) >= 0 {
+		return WormholeName
+	}
+	return ""
+}
+
+//Cost returns the number of moves needed to travel the road in the given direction
+func (cn *CityNode) Cost(direction int) int {
+	if cost, ok := cn.Costs[direction]; ok && cost > 1 {
+		return cost
+	}
+	return 1
+}
+
+//SetCost sets the number of moves needed to travel the road in the given direction
+func (cn *CityNode) SetCost(direction, cost int) {
+	if cost <= 1 {
+		delete(cn.Costs, direction)
+		return
+	}
+	if cn.Costs == nil {
+		cn.Costs = make(map[int]int)
+	}
+	cn.Costs[direction] = cost
+}
+
+//IsOneWay tells whether the road in the given direction is one-way
+func (cn *CityNode) IsOneWay(direction int) bool {
+	return cn.OneWay&direction > 0
+}
+
+//Opposite returns the direction pointing back, e.g. West for East
+func Opposite(direction int) int {
+	switch direction {
+	case East:
+		return West
+	case West:
+		return East
+	case North:
+		return South
+	case South:
+		return North
+	}
+	return 0
+}
+
+//directionByName maps a direction name in the map file to its bit value
+func directionByName(name string) int {
+	for i, dn := range DirectionNames {
+		if dn == name {
+			return DirectionBitMap[i]
+		}
+	}
+	return 0
 }
 
 //NewRandNumGen returns a RandNumArrayGen object
@@ -92,17 +281,22 @@ func (rna *RandNumArrayGen) GenerateNums(num int) []int {
 }
 
 //GenerateNames returns numbers of random names from the predefined list
+//When the list runs out, procedurally generated names are added
 func GenerateNames(generator NumArrayGen, nameList []string, num int) ([]string, error) {
-	if num > len(nameList) {
-		return nil, ErrReqTooLarge
+	if num < 0 {
+		return nil, ErrInvalidInput
+	}
+	count := num
+	if count > len(nameList) {
+		count = len(nameList)
 	}
 
-	nums := generator.GenerateNums(num)
+	nums := generator.GenerateNums(count)
 	names := make([]string, 0, num)
 	for _, num := range nums {
 		names = append(names, nameList[num])
 	}
-	return names, nil
+	return append(names, GenerateProceduralNames(generator, num-count, nameList)...), nil
 }
 
 //GenerateCityNames returns a list of city names
@@ -147,10 +341,7 @@ func getDirectionValue(neighbors [4]int, rg NumGen) int {
 //{0,     East | North, West | East,   West | North},
 //Note, the first row and first column are there to help generate direction masks
 func GenerateDirectionMask(x, y int, rg NumGen) ([][]int, error) {
-	if x*y > len(CityNames) {
-		return nil, ErrReqTooLarge
-	}
-	if x == 0 || y == 0 {
+	if x <= 0 || y <= 0 {
 		return nil, ErrInvalidInput
 	}
 	//Make an extra rown and an extra column in order to make
@@ -239,11 +430,21 @@ func GenerateCityMap(mask [][]int, cityNames []string) map[string]*CityNode {
 
 //GenerateCityMapFromSteam reads city map from stream
 //It can be from a real file, or a string stream for testing purpose
+//Lines starting with '#' are skipped
+//A road is written as "east=B" (two-way) or "east=>B" (one-way, from this city to B)
+//and may carry a travel cost in moves, e.g. "east=B:3". The default cost is 1
+//Wormholes are written as "wormhole=B", and can be one-way or cost more
+//than a move just like roads. A city can have up to MaxWormholes of them
+//The state of the city is written as "defense=2" and "damage=30"
 func GenerateCityMapFromSteam(scanner *bufio.Scanner, splitter rune) map[string]*CityNode {
 	cm := make(map[string]*CityNode)
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		//lines starting with '#' are comments
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
 
 		//when input is string, it's by default tokenizing by whitespace
 		//so, add a special splitter helper to allow specifying other character
@@ -263,20 +464,45 @@ func GenerateCityMapFromSteam(scanner *bufio.Scanner, splitter rune) map[string]
 				if len(directStrs) != 2 {
 					log.Panic("invalid direction map")
 				}
-				city, ok := cm[directStrs[1]]
+				if state := cityState(cm[cityName], directStrs[0]); state != nil {
+					value, err := strconv.Atoi(directStrs[1])
+					if err != nil || value < 0 {
+						log.Panic("invalid city " + directStrs[0])
+					}
+					*state = value
+					continue
+				}
+				neighbor, cost := directStrs[1], 1
+				oneWay := strings.HasPrefix(neighbor, ">")
+				if oneWay {
+					neighbor = neighbor[1:]
+				}
+				if i := strings.LastIndex(neighbor, ":"); i >= 0 {
+					var err error
+					if cost, err = strconv.Atoi(neighbor[i+1:]); err != nil || cost < 1 {
+						log.Panic("invalid road cost")
+					}
+					neighbor = neighbor[:i]
+				}
+				city, ok := cm[neighbor]
 				if !ok {
-					city = &CityNode{Name: directStrs[1], Aliens: make([]string, 0, 20)}
-					cm[directStrs[1]] = city
+					city = &CityNode{Name: neighbor, Aliens: make([]string, 0, 20)}
+					cm[neighbor] = city
 				}
-				switch directStrs[0] {
-				case "east":
-					cm[cityName].East = city
-				case "west":
-					cm[cityName].West = city
-				case "north":
-					cm[cityName].North = city
-				case "south":
-					cm[cityName].South = city
+				direction := directionByName(directStrs[0])
+				if directStrs[0] == WormholeName {
+					if len(cm[cityName].Wormholes) >= MaxWormholes {
+						log.Panic("too many wormholes")
+					}
+					direction = Wormhole << len(cm[cityName].Wormholes)
+				}
+				if direction == 0 {
+					continue
+				}
+				cm[cityName].SetNeighbor(direction, city)
+				cm[cityName].SetCost(direction, cost)
+				if oneWay {
+					cm[cityName].OneWay |= direction
 				}
 			}
 
@@ -288,24 +514,91 @@ func GenerateCityMapFromSteam(scanner *bufio.Scanner, splitter rune) map[string]
 	return cm
 }
 
+//cityState returns the field of the city keeping the state named in the map file,
+//or nil when name is not a state
+func cityState(city *CityNode, name string) *int {
+	switch name {
+	case "defense":
+		return &city.Defense
+	case "damage":
+		return &city.Damage
+	}
+	return nil
+}
+
+//GenerateDefenses gives each city without a defense a random one
+//within 0-max, going through the cities in sorted order
+func GenerateDefenses(cm map[string]*CityNode, max int, rg NumGen) {
+	if max <= 0 {
+		return
+	}
+	for _, city := range SortedCityNames(cm) {
+		if cm[city].Defense == 0 {
+			cm[city].Defense = rg.GenerateNum(max + 1)
+		}
+	}
+}
+
+//SortedCityNames returns the names of the cities in the map in sorted order,
+//which gives a stable order to go through the map
+func SortedCityNames(cm map[string]*CityNode) []string {
+	names := make([]string, 0, len(cm))
+	for city := range cm {
+		names = append(names, city)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//CloneCityMap returns a deep copy of the city map, whose roads and
+//wormholes lead to the copied cities
+func CloneCityMap(cm map[string]*CityNode) map[string]*CityNode {
+	clone := make(map[string]*CityNode, len(cm))
+	for city, node := range cm {
+		clone[city] = &CityNode{
+			Name:    node.Name,
+			Aliens:  append([]string(nil), node.Aliens...),
+			Damage:  node.Damage,
+			Defense: node.Defense,
+		}
+	}
+	for city, node := range cm {
+		copied := clone[city]
+		for _, direction := range node.Links() {
+			copied.SetNeighbor(direction, clone[node.Neighbor(direction).Name])
+			copied.SetCost(direction, node.Cost(direction))
+		}
+		copied.OneWay = node.OneWay
+	}
+	return clone
+}
+
 //GenerateMapFile writes the map info into output source
+//Cities are written in sorted order, so the same map always gives the same output
 func GenerateMapFile(cm map[string]*CityNode, w io.Writer) {
 	bufWriter := bufio.NewWriter(w)
 
-	for city, node := range cm {
+	for _, city := range SortedCityNames(cm) {
+		node := cm[city]
 		coordinates := make([]string, 0, 6)
 		coordinates = append(coordinates, city)
-		if node.East != nil {
-			coordinates = append(coordinates, "east="+node.East.Name)
-		}
-		if node.West != nil {
-			coordinates = append(coordinates, "west="+node.West.Name)
+		for _, direction := range node.Links() {
+			neighbor := node.Neighbor(direction)
+			road := LinkName(direction) + "="
+			if node.IsOneWay(direction) {
+				road += ">"
+			}
+			road += neighbor.Name
+			if cost := node.Cost(direction); cost > 1 {
+				road += ":" + strconv.Itoa(cost)
+			}
+			coordinates = append(coordinates, road)
 		}
-		if node.North != nil {
-			coordinates = append(coordinates, "north="+node.North.Name)
+		if node.Defense > 0 {
+			coordinates = append(coordinates, "defense="+strconv.Itoa(node.Defense))
 		}
-		if node.South != nil {
-			coordinates = append(coordinates, "south="+node.South.Name)
+		if node.Damage > 0 {
+			coordinates = append(coordinates, "damage="+strconv.Itoa(node.Damage))
 		}
 		if len(coordinates) == 1 {
 			continue
@@ -315,3 +608,29 @@ func GenerateMapFile(cm map[string]*CityNode, w io.Writer) {
 	}
 	bufWriter.Flush()
 }
+
+//ValidateCityMap checks that the roads of the city map are consistent.
+//Every two-way road must have a matching two-way road back with the same
+//cost, e.g. when A has "east=B", B must have "west=A", and every two-way
+//wormhole a wormhole back. One-way roads need no road back
+func ValidateCityMap(cm map[string]*CityNode) error {
+	for _, city := range SortedCityNames(cm) {
+		node := cm[city]
+		for _, direction := range node.Links() {
+			neighbor := node.Neighbor(direction)
+			if node.IsOneWay(direction) {
+				continue
+			}
+			back := node.Back(direction)
+			if back == 0 || neighbor.IsOneWay(back) {
+				return fmt.Errorf("%v, two-way road %s %s=%s has no road back",
+					ErrInvalidMap, city, LinkName(direction), neighbor.Name)
+			}
+			if neighbor.Cost(back) != node.Cost(direction) {
+				return fmt.Errorf("%v, two-way road %s %s=%s costs differ in each direction",
+					ErrInvalidMap, city, LinkName(direction), neighbor.Name)
+			}
+		}
+	}
+	return nil
+}
@@ -62,8 +62,14 @@ func TestGenerateCitiesOrAlienNames(t *testing.T) {
 		{
 			len(CityNames) + 1,
 			true,
+			append(append([]string{}, CityNames...), "Baba"),
 			nil,
-			ErrReqTooLarge,
+		},
+		{
+			-1,
+			true,
+			nil,
+			ErrInvalidInput,
 		},
 		{
 			2,
@@ -78,10 +84,10 @@ func TestGenerateCitiesOrAlienNames(t *testing.T) {
 			nil,
 		},
 		{
-			len(AlienNames) + 1,
+			len(AlienNames) + 2,
 			false,
+			append(append([]string{}, AlienNames...), "Baba", "Babe"),
 			nil,
-			ErrReqTooLarge,
 		},
 	}
 	for _, tt := range tests {
@@ -120,8 +126,8 @@ func TestGenerateDirectionMask(t *testing.T) {
 			nil, ErrInvalidInput,
 		},
 		{
-			100, 10, fakeOneGenerator,
-			nil, ErrReqTooLarge,
+			-1, 2, fakeOneGenerator,
+			nil, ErrInvalidInput,
 		},
 		{
 			3, 3, fakeOneGenerator,
@@ -149,6 +155,18 @@ func TestGenerateDirectionMask(t *testing.T) {
 	}
 }
 
+func TestGenerateLargeMap(t *testing.T) {
+	assert := assert.New(t)
+
+	masks, err := GenerateDirectionMask(100, 10, fakeOneGenerator)
+	assert.Nil(err)
+	cityNames, err := GenerateCityNames(fakeArrGenerator, 100*10)
+	assert.Nil(err)
+	cityMap := GenerateCityMap(masks, cityNames)
+	assert.Equal(100*10, len(cityMap))
+	assert.Nil(ValidateCityMap(cityMap))
+}
+
 func TestGenerateCityMapNeg(t *testing.T) {
 	assert := assert.New(t)
 
@@ -268,6 +286,17 @@ func TestGenerateCityMapFromSteam(t *testing.T) {
 	assert.Equal("Foo", cityMap["Bar"].South.Name)
 }
 
+func TestGenerateCityMapFromSteamComments(t *testing.T) {
+	assert := assert.New(t)
+
+	input := "# seed 42\nFoo north=Bar\n  # Bar south=Foo\n"
+	cityMap := GenerateCityMapFromSteam(bufio.NewScanner(strings.NewReader(input)), ' ')
+
+	assert.Equal(2, len(cityMap))
+	assert.Equal("Bar", cityMap["Foo"].North.Name)
+	assert.Nil(cityMap["Bar"].South)
+}
+
 func TestGenerateMapFile(t *testing.T) {
 	assert := assert.New(t)
 	var b bytes.Buffer
@@ -281,3 +310,199 @@ func TestGenerateMapFile(t *testing.T) {
 	GenerateMapFile(cityMap, &b)
 	assert.Equal("Foo east=Bee west=Baz north=Bar south=Qu-ux \n", b.String())
 }
+
+func TestGenerateMapFileSorted(t *testing.T) {
+	assert := assert.New(t)
+	var b bytes.Buffer
+
+	input := "Foo,west=Bar Bar,east=Foo Baz,north=Foo"
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(bufio.ScanWords)
+
+	cityMap := GenerateCityMapFromSteam(scanner, ',')
+	assert.Equal([]string{"Bar", "Baz", "Foo"}, SortedCityNames(cityMap))
+
+	GenerateMapFile(cityMap, &b)
+	assert.Equal("Bar east=Foo \nBaz north=Foo \nFoo west=Bar \n", b.String())
+}
+
+func TestOneWayRoads(t *testing.T) {
+	assert := assert.New(t)
+	var b bytes.Buffer
+
+	input := "Foo,east=>Bar"
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(bufio.ScanWords)
+
+	cityMap := GenerateCityMapFromSteam(scanner, ',')
+
+	assert.Equal("Bar", cityMap["Foo"].Neighbor(East).Name)
+	assert.True(cityMap["Foo"].IsOneWay(East))
+	assert.Nil(cityMap["Bar"].Neighbor(West))
+	assert.Nil(ValidateCityMap(cityMap))
+
+	GenerateMapFile(cityMap, &b)
+	assert.Equal("Foo east=>Bar \n", b.String())
+
+	cityMap["Foo"].SetNeighbor(East, cityMap["Bar"])
+	assert.False(cityMap["Foo"].IsOneWay(East))
+}
+
+func TestValidateCityMap(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		input string
+		valid bool
+	}{
+		{"Foo,north=Bar Bar,south=Foo", true},
+		{"Foo,north=>Bar Bar,south=>Foo", true},
+		{"Foo,north=>Bar Bar,west=Baz Baz,east=Bar", true},
+		{"Foo,north=Bar", false},
+		{"Foo,north=Bar Bar,south=>Foo", false},
+		{"Foo,north=Bar Bar,west=Foo Foo,east=Bar", false},
+	}
+
+	for _, tt := range tests {
+		scanner := bufio.NewScanner(strings.NewReader(tt.input))
+		scanner.Split(bufio.ScanWords)
+		err := ValidateCityMap(GenerateCityMapFromSteam(scanner, ','))
+		if tt.valid {
+			assert.Nil(err, tt.input)
+		} else {
+			assert.NotNil(err, tt.input)
+		}
+	}
+
+	masks, _ := GenerateDirectionMask(3, 3, fakeOneGenerator)
+	cityNames, _ := GenerateCityNames(fakeArrGenerator, 9)
+	assert.Nil(ValidateCityMap(GenerateCityMap(masks, cityNames)))
+}
+
+func TestRoadCosts(t *testing.T) {
+	assert := assert.New(t)
+	var b bytes.Buffer
+
+	input := "Foo,east=>Bar:3"
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(bufio.ScanWords)
+
+	cityMap := GenerateCityMapFromSteam(scanner, ',')
+
+	assert.Equal("Bar", cityMap["Foo"].East.Name)
+	assert.Equal(3, cityMap["Foo"].Cost(East))
+	assert.Equal(1, cityMap["Foo"].Cost(West))
+
+	GenerateMapFile(cityMap, &b)
+	assert.Equal("Foo east=>Bar:3 \n", b.String())
+
+	cityMap["Foo"].SetNeighbor(East, cityMap["Bar"])
+	assert.Equal(1, cityMap["Foo"].Cost(East))
+
+	scanner = bufio.NewScanner(strings.NewReader("Foo,east=Bar:2 Bar,west=Foo"))
+	scanner.Split(bufio.ScanWords)
+	assert.NotNil(ValidateCityMap(GenerateCityMapFromSteam(scanner, ',')))
+
+	scanner = bufio.NewScanner(strings.NewReader("Foo,east=Bar:0"))
+	scanner.Split(bufio.ScanWords)
+	assert.Panics(func() { GenerateCityMapFromSteam(scanner, ',') })
+}
+
+func TestCityState(t *testing.T) {
+	assert := assert.New(t)
+	var b bytes.Buffer
+
+	input := "Foo,defense=2,east=Bar Bar,west=Foo,damage=30 Baz,defense=1"
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(bufio.ScanWords)
+
+	cityMap := GenerateCityMapFromSteam(scanner, ',')
+	assert.Equal(3, len(cityMap))
+	assert.Equal(2, cityMap["Foo"].Defense)
+	assert.Equal(30, cityMap["Bar"].Damage)
+	assert.Equal("Bar", cityMap["Foo"].East.Name)
+
+	GenerateMapFile(cityMap, &b)
+	assert.Equal("Bar west=Foo damage=30 \nBaz defense=1 \nFoo east=Bar defense=2 \n", b.String())
+
+	for _, input := range []string{"Foo,defense=x", "Foo,damage=-1"} {
+		scanner = bufio.NewScanner(strings.NewReader(input))
+		scanner.Split(bufio.ScanWords)
+		assert.Panics(func() { GenerateCityMapFromSteam(scanner, ',') }, input)
+	}
+}
+
+func TestGenerateDefenses(t *testing.T) {
+	assert := assert.New(t)
+
+	masks, _ := GenerateDirectionMask(2, 2, fakeOneGenerator)
+	cityNames, _ := GenerateCityNames(fakeArrGenerator, 4)
+	cityMap := GenerateCityMap(masks, cityNames)
+	cityMap[cityNames[0]].Defense = 5
+
+	GenerateDefenses(cityMap, 3, fakeOneGenerator)
+	assert.Equal(5, cityMap[cityNames[0]].Defense)
+	for _, city := range cityNames[1:] {
+		assert.Equal(1, cityMap[city].Defense)
+	}
+
+	GenerateDefenses(cityMap, 0, nil)
+}
+
+func TestWormholes(t *testing.T) {
+	assert := assert.New(t)
+	var b bytes.Buffer
+
+	input := "Foo,east=Bar,wormhole=Baz,wormhole=>Qux:3 Bar,west=Foo Baz,wormhole=Foo"
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(bufio.ScanWords)
+
+	cityMap := GenerateCityMapFromSteam(scanner, ',')
+	foo := cityMap["Foo"]
+	assert.Equal(4, len(cityMap))
+	assert.Equal("Baz", foo.Neighbor(Wormhole).Name)
+	assert.Equal("Qux", foo.Neighbor(Wormhole<<1).Name)
+	assert.True(foo.IsOneWay(Wormhole << 1))
+	assert.Equal(3, foo.Cost(Wormhole<<1))
+	assert.Equal([]int{East, Wormhole, Wormhole << 1}, foo.Links())
+	assert.Equal(West, foo.Back(East))
+	assert.Equal(Wormhole, foo.Back(Wormhole))
+	assert.Equal(0, foo.Back(Wormhole<<1))
+	assert.Nil(foo.Neighbor(Wormhole << 2))
+	assert.Nil(ValidateCityMap(cityMap))
+
+	GenerateMapFile(cityMap, &b)
+	assert.Equal("Bar west=Foo \nBaz wormhole=Foo \nFoo east=Bar wormhole=Baz wormhole=>Qux:3 \n", b.String())
+
+	foo.SetNeighbor(Wormhole, nil)
+	assert.Equal([]int{East, Wormhole << 1}, foo.Links())
+	assert.NotNil(ValidateCityMap(cityMap))
+
+	scanner = bufio.NewScanner(strings.NewReader("Foo" + strings.Repeat(",wormhole=Bar", MaxWormholes+1)))
+	scanner.Split(bufio.ScanWords)
+	assert.Panics(func() { GenerateCityMapFromSteam(scanner, ',') })
+}
+
+func TestCloneCityMap(t *testing.T) {
+	assert := assert.New(t)
+	var original, cloned bytes.Buffer
+
+	input := "Foo,east=Bar:2,wormhole=>Baz,defense=2 Bar,west=Foo:2,damage=10 Baz"
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(bufio.ScanWords)
+	cityMap := GenerateCityMapFromSteam(scanner, ',')
+
+	clone := CloneCityMap(cityMap)
+	GenerateMapFile(cityMap, &original)
+	GenerateMapFile(clone, &cloned)
+	assert.Equal(original.String(), cloned.String())
+	assert.True(clone["Foo"].East == clone["Bar"])
+	assert.True(clone["Foo"].Neighbor(Wormhole) == clone["Baz"])
+
+	//changing the clone leaves the map alone
+	clone["Foo"].SetNeighbor(East, nil)
+	clone["Bar"].Defense = 1
+	assert.Equal(cityMap["Bar"], cityMap["Foo"].East)
+	assert.Equal(2, cityMap["Foo"].Cost(East))
+	assert.Equal(0, cityMap["Bar"].Defense)
+}
@@ -0,0 +1,119 @@
+package generators
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+var (
+	//ErrInvalidName is returned when a name cannot be used in the game
+	ErrInvalidName = fmt.Errorf("invalid name")
+
+	//Syllables holds the syllables procedural names are made of. Every syllable
+	//is a consonant followed by a vowel, so different syllable sequences always
+	//spell different names
+	Syllables = makeSyllables("bdfgklmnprstvz", "aeiou")
+)
+
+func makeSyllables(consonants, vowels string) []string {
+	syllables := make([]string, 0, len(consonants)*len(vowels))
+	for _, c := range consonants {
+		for _, v := range vowels {
+			syllables = append(syllables, string(c)+string(v))
+		}
+	}
+	return syllables
+}
+
+//GenerateProceduralNames returns num unique names built from Syllables,
+//none of which is in the taken list. The generator decides the order in
+//which two-syllable names are handed out. Once all of them are used, further
+//syllables are appended, so there is no upper limit on the number of names
+func GenerateProceduralNames(generator NumArrayGen, num int, taken []string) []string {
+	if num <= 0 {
+		return nil
+	}
+	takenSet := make(map[string]bool, len(taken))
+	for _, name := range taken {
+		takenSet[name] = true
+	}
+
+	s := len(Syllables)
+	pairs := generator.GenerateNums(s * s)
+	names := make([]string, 0, num)
+	for i := 0; len(names) < num; i++ {
+		pair := pairs[i%len(pairs)]
+		var b strings.Builder
+		b.WriteString(strings.ToUpper(Syllables[pair/s][:1]))
+		b.WriteString(Syllables[pair/s][1:])
+		b.WriteString(Syllables[pair%s])
+		//bijective base-s numbering, so every round gets a distinct suffix
+		for round := i / len(pairs); round > 0; round = (round - 1) / s {
+			b.WriteString(Syllables[(round-1)%s])
+		}
+		if name := b.String(); !takenSet[name] {
+			takenSet[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+//ReadNames reads a list of names. JSON input is an array of strings, otherwise
+//each line holds one name. Blank lines and lines starting with '#' are skipped
+func ReadNames(r io.Reader, isJSON bool) ([]string, error) {
+	var names []string
+	if isJSON {
+		if err := json.NewDecoder(r).Decode(&names); err != nil {
+			return nil, err
+		}
+		return names, nil
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	return names, scanner.Err()
+}
+
+//MergeNames returns base followed by the names in extra which are not in
+//the result yet, so the merged list has no duplicates
+func MergeNames(base, extra []string) []string {
+	seen := make(map[string]bool, len(base)+len(extra))
+	merged := make([]string, 0, len(base)+len(extra))
+	for _, name := range append(append([]string{}, base...), extra...) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		merged = append(merged, name)
+	}
+	return merged
+}
+
+//ValidateCityName checks that the name can be written into a map file, that
+//is it is not empty, has no whitespace and none of '=', ':' and '>', and does
+//not start with '#', which starts a comment line
+func ValidateCityName(name string) error {
+	if name == "" || strings.IndexFunc(name, unicode.IsSpace) >= 0 || strings.ContainsAny(name, "=:>") ||
+		strings.HasPrefix(name, "#") {
+		return fmt.Errorf("%v, %q cannot be used as a city name", ErrInvalidName, name)
+	}
+	return nil
+}
+
+//ValidateAlienName checks that the name is not empty and fits on a log line
+func ValidateAlienName(name string) error {
+	if strings.TrimSpace(name) == "" || strings.ContainsAny(name, "\r\n") {
+		return fmt.Errorf("%v, %q cannot be used as an alien name", ErrInvalidName, name)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package generators
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateProceduralNames(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Empty(GenerateProceduralNames(fakeArrGenerator, 0, nil))
+	assert.Equal([]string{"Baba", "Babe", "Babi"}, GenerateProceduralNames(fakeArrGenerator, 3, nil))
+	assert.Equal([]string{"Babe", "Babi"}, GenerateProceduralNames(fakeArrGenerator, 2, []string{"Baba"}))
+
+	s := len(Syllables)
+	num := s*s*2 + 10
+	names := GenerateProceduralNames(NewRandNumGen(), num, CityNames)
+	assert.Equal(num, len(names))
+
+	unique := make(map[string]bool)
+	for _, name := range names {
+		unique[name] = true
+	}
+	assert.Equal(num, len(unique))
+	assert.Equal("Bababa", GenerateProceduralNames(fakeArrGenerator, s*s+1, nil)[s*s])
+}
+
+func TestReadNames(t *testing.T) {
+	assert := assert.New(t)
+
+	names, err := ReadNames(strings.NewReader("Foo\n\n# comment\n  Bar \nFoo\n"), false)
+	assert.Nil(err)
+	assert.Equal([]string{"Foo", "Bar", "Foo"}, names)
+
+	names, err = ReadNames(strings.NewReader(`["Foo", "Bar Baz"]`), true)
+	assert.Nil(err)
+	assert.Equal([]string{"Foo", "Bar Baz"}, names)
+
+	_, err = ReadNames(strings.NewReader(`{"Foo": 1}`), true)
+	assert.NotNil(err)
+}
+
+func TestMergeNames(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"Foo", "Bar", "Baz"}, MergeNames([]string{"Foo", "Bar"}, []string{"Bar", "Baz", "Foo"}))
+	assert.Equal([]string{"Foo"}, MergeNames(nil, []string{"Foo", "Foo"}))
+	assert.Empty(MergeNames(nil, nil))
+}
+
+func TestValidateNames(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, name := range []string{"Foo", "Qu-ux", "Lüdazhuang", "Foo#1"} {
+		assert.Nil(ValidateCityName(name), name)
+	}
+	for _, name := range []string{"", "Hongpan Xiang", "Foo=Bar", "Foo:3", ">Foo", "Foo\tBar", "#Foo"} {
+		assert.NotNil(ValidateCityName(name), name)
+	}
+
+	assert.Nil(ValidateAlienName("NeFlav Yucholl"))
+	assert.NotNil(ValidateAlienName(" "))
+	assert.NotNil(ValidateAlienName("Foo\nBar"))
+}
@@ -0,0 +1,67 @@
+package generators
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+//SplittableNumGen is a NumGen which can derive independent streams of numbers.
+//A stream only depends on the generator's seed and the stream name, not on
+//how many numbers were drawn from the generator or from other streams
+type SplittableNumGen interface {
+	NumGen
+	Split(name string) NumGen
+}
+
+//SeededNumGen implements both NumGen and NumArrayGen on top of its own
+//random source, so the same seed always produces the same numbers
+//It also implements SplittableNumGen
+type SeededNumGen struct {
+	seed int64
+	rnd  *rand.Rand
+}
+
+//NewSeededNumGen returns a SeededNumGen object seeded with seed
+func NewSeededNumGen(seed int64) *SeededNumGen {
+	return &SeededNumGen{seed: seed, rnd: rand.New(rand.NewSource(seed))}
+}
+
+//Seed returns the seed the generator started from
+func (sg *SeededNumGen) Seed() int64 {
+	return sg.seed
+}
+
+//GenerateNum implements NumGen interface
+func (sg *SeededNumGen) GenerateNum(n int) int {
+	return sg.rnd.Intn(n)
+}
+
+//GenerateNums implements NumArrayGen interface
+func (sg *SeededNumGen) GenerateNums(num int) []int {
+	if num <= 0 {
+		return nil
+	}
+	return sg.rnd.Perm(num)
+}
+
+//Stream returns a new generator whose seed is derived from this generator's
+//seed and name. Different names give unrelated streams
+func (sg *SeededNumGen) Stream(name string) *SeededNumGen {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return NewSeededNumGen(int64(mix64(uint64(sg.seed) ^ mix64(h.Sum64()))))
+}
+
+//Split implements SplittableNumGen interface
+func (sg *SeededNumGen) Split(name string) NumGen {
+	return sg.Stream(name)
+}
+
+//mix64 is the finalizer of splitmix64. It spreads close inputs, such as
+//consecutive seeds, far apart
+func mix64(z uint64) uint64 {
+	z += 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
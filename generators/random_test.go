@@ -0,0 +1,46 @@
+package generators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeededNumGen(t *testing.T) {
+	assert := assert.New(t)
+
+	first, second := NewSeededNumGen(42), NewSeededNumGen(42)
+	assert.Equal(int64(42), first.Seed())
+	for i := 0; i < 10; i++ {
+		assert.Equal(first.GenerateNum(100), second.GenerateNum(100))
+	}
+	assert.Equal(first.GenerateNums(20), second.GenerateNums(20))
+	assert.Nil(first.GenerateNums(0))
+
+	firstMasks, _ := GenerateDirectionMask(5, 5, NewSeededNumGen(7))
+	secondMasks, _ := GenerateDirectionMask(5, 5, NewSeededNumGen(7))
+	assert.Equal(firstMasks, secondMasks)
+
+	firstNames, _ := GenerateCityNames(NewSeededNumGen(7), len(CityNames)+5)
+	secondNames, _ := GenerateCityNames(NewSeededNumGen(7), len(CityNames)+5)
+	assert.Equal(firstNames, secondNames)
+}
+
+func TestSeededNumGenStreams(t *testing.T) {
+	assert := assert.New(t)
+
+	first, second := NewSeededNumGen(42), NewSeededNumGen(42)
+	//drawing from the parent does not change the streams
+	first.GenerateNums(10)
+	assert.Equal(first.Stream("movement").GenerateNums(20), second.Stream("movement").GenerateNums(20))
+	assert.NotEqual(first.Stream("movement").GenerateNums(20), first.Stream("placement").GenerateNums(20))
+	assert.NotEqual(first.Stream("movement").GenerateNums(20), NewSeededNumGen(43).Stream("movement").GenerateNums(20))
+
+	var gen NumGen = first
+	splittable, ok := gen.(SplittableNumGen)
+	assert.True(ok)
+	stream, other := splittable.Split("alien"), second.Split("alien")
+	for i := 0; i < 10; i++ {
+		assert.Equal(stream.GenerateNum(1000), other.GenerateNum(1000))
+	}
+}
@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"container/heap"
+
+	"github.com/hatricker/alieninvasion/generators"
+)
+
+//Reach describes a city found when walking the roads from a start city
+//Distance is the total cost of the roads taken and FirstStep the direction
+//of the first road leaving the start city, 0 for the start city itself
+type Reach struct {
+	City      *generators.CityNode
+	Distance  int
+	FirstStep int
+}
+
+//item is an entry of the priority queue used by the searches
+//previous and via tell where the city was reached from and by which road
+//seq breaks ties between equal distances, so results are stable
+type item struct {
+	Reach
+	previous *generators.CityNode
+	via      int
+	seq      int
+}
+
+type queue []item
+
+func (q queue) Len() int { return len(q) }
+func (q queue) Less(i, j int) bool {
+	if q[i].Distance != q[j].Distance {
+		return q[i].Distance < q[j].Distance
+	}
+	return q[i].seq < q[j].seq
+}
+func (q queue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x interface{}) { *q = append(*q, x.(item)) }
+func (q *queue) Pop() interface{} {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
+
+//search walks the roads from the sources in order of distance, following
+//one-way roads only in their direction and paying each road's cost
+//Cities further than radius are not visited, a radius of 0 means no limit
+//visit is called once for each city reached along its cheapest way,
+//stopping when it returns false
+func search(sources []*generators.CityNode, radius int, visit func(item) bool) {
+	done := make(map[*generators.CityNode]bool)
+	q := &queue{}
+	seq := 0
+	for _, source := range sources {
+		heap.Push(q, item{Reach: Reach{City: source}, seq: seq})
+		seq++
+	}
+	for q.Len() > 0 {
+		it := heap.Pop(q).(item)
+		if done[it.City] {
+			continue
+		}
+		done[it.City] = true
+		if !visit(it) {
+			return
+		}
+		for _, direction := range it.City.Links() {
+			next := it.City.Neighbor(direction)
+			if done[next] {
+				continue
+			}
+			distance := it.Distance + it.City.Cost(direction)
+			if radius > 0 && distance > radius {
+				continue
+			}
+			firstStep := it.FirstStep
+			if firstStep == 0 && it.Distance == 0 {
+				firstStep = direction
+			}
+			heap.Push(q, item{
+				Reach:    Reach{City: next, Distance: distance, FirstStep: firstStep},
+				previous: it.City,
+				via:      direction,
+				seq:      seq,
+			})
+			seq++
+		}
+	}
+}
+
+//Explore returns the cities reachable from start within radius ordered by
+//distance, starting with start itself. A radius of 0 means no limit
+func Explore(start *generators.CityNode, radius int) []Reach {
+	var reached []Reach
+	search([]*generators.CityNode{start}, radius, func(it item) bool {
+		reached = append(reached, it.Reach)
+		return true
+	})
+	return reached
+}
+
+//Nearest returns the closest city within radius from start, start included,
+//for which match returns true. ok is false when there is no such city
+func Nearest(start *generators.CityNode, radius int, match func(*generators.CityNode) bool) (reach Reach, ok bool) {
+	search([]*generators.CityNode{start}, radius, func(it item) bool {
+		if match(it.City) {
+			reach, ok = it.Reach, true
+		}
+		return !ok
+	})
+	return reach, ok
+}
+
+//Distances returns the distance from the closest of the sources to every
+//city reachable from them within radius, keyed by city name
+func Distances(sources []*generators.CityNode, radius int) map[string]int {
+	distances := make(map[string]int)
+	search(sources, radius, func(it item) bool {
+		distances[it.City.Name] = it.Distance
+		return true
+	})
+	return distances
+}
+
+//ShortestPath returns the directions of the roads to take to go from one
+//city to another along the cheapest way, nil when to cannot be reached
+func ShortestPath(from, to *generators.CityNode) []int {
+	reached := make(map[*generators.CityNode]item)
+	search([]*generators.CityNode{from}, 0, func(it item) bool {
+		reached[it.City] = it
+		return it.City != to
+	})
+	if _, ok := reached[to]; !ok {
+		return nil
+	}
+	path := []int{}
+	for city := to; city != from; city = reached[city].previous {
+		path = append([]int{reached[city].via}, path...)
+	}
+	return path
+}
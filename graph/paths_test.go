@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/hatricker/alieninvasion/generators"
+	"github.com/stretchr/testify/assert"
+)
+
+//testing map, a 2x3 grid where the road from A to B is slow
+//  A - B - C
+//  |       |
+//  D - E - F
+const testingMap = `A east=B:6 south=D
+B west=A:6 east=C
+C west=B south=F
+D north=A east=E
+E west=D east=F
+F west=E north=C`
+
+func generateCityMap() map[string]*generators.CityNode {
+	return generators.GenerateCityMapFromSteam(bufio.NewScanner(strings.NewReader(testingMap)), ' ')
+}
+
+func names(reached []Reach) []string {
+	result := make([]string, 0, len(reached))
+	for _, r := range reached {
+		result = append(result, r.City.Name)
+	}
+	return result
+}
+
+func TestExplore(t *testing.T) {
+	assert := assert.New(t)
+	cm := generateCityMap()
+
+	reached := Explore(cm["A"], 0)
+	assert.Equal([]string{"A", "D", "E", "F", "C", "B"}, names(reached))
+	assert.Equal(Reach{City: cm["A"]}, reached[0])
+	assert.Equal(Reach{City: cm["C"], Distance: 4, FirstStep: generators.South}, reached[4])
+	//the slow road is not the cheapest way to B
+	assert.Equal(Reach{City: cm["B"], Distance: 5, FirstStep: generators.South}, reached[5])
+
+	assert.Equal([]string{"A", "D", "E"}, names(Explore(cm["A"], 2)))
+
+	//one-way roads are only followed forward
+	cm["D"].OneWay |= generators.North
+	cm["A"].SetNeighbor(generators.South, nil)
+	assert.Equal([]string{"D", "E", "A", "F", "C", "B"}, names(Explore(cm["D"], 0)))
+	assert.Equal([]string{"A"}, names(Explore(cm["A"], 1)))
+}
+
+func TestNearest(t *testing.T) {
+	assert := assert.New(t)
+	cm := generateCityMap()
+
+	reach, ok := Nearest(cm["A"], 0, func(c *generators.CityNode) bool { return c.Name == "B" || c.Name == "F" })
+	assert.True(ok)
+	assert.Equal(Reach{City: cm["F"], Distance: 3, FirstStep: generators.South}, reach)
+
+	_, ok = Nearest(cm["A"], 2, func(c *generators.CityNode) bool { return c.Name == "F" })
+	assert.False(ok)
+}
+
+func TestDistances(t *testing.T) {
+	assert := assert.New(t)
+	cm := generateCityMap()
+
+	assert.Equal(map[string]int{"A": 0, "B": 2, "C": 1, "D": 1, "E": 1, "F": 0},
+		Distances([]*generators.CityNode{cm["A"], cm["F"]}, 0))
+	assert.Equal(map[string]int{"A": 0, "D": 1}, Distances([]*generators.CityNode{cm["A"]}, 1))
+}
+
+func TestShortestPath(t *testing.T) {
+	assert := assert.New(t)
+	cm := generateCityMap()
+	e, w, n, s := generators.East, generators.West, generators.North, generators.South
+
+	assert.Equal([]int{s, e, e, n, w}, ShortestPath(cm["A"], cm["B"]))
+	assert.Equal([]int{w, w}, ShortestPath(cm["F"], cm["D"]))
+	assert.Equal([]int{}, ShortestPath(cm["A"], cm["A"]))
+
+	cm["C"].SetNeighbor(w, nil)
+	cm["B"].SetNeighbor(e, nil)
+	assert.Equal([]int{e}, ShortestPath(cm["A"], cm["B"]))
+	cm["A"].SetNeighbor(e, nil)
+	assert.Nil(ShortestPath(cm["A"], cm["B"]))
+}
+
+func TestWormholePaths(t *testing.T) {
+	assert := assert.New(t)
+	cm := generateCityMap()
+	cm["A"].SetNeighbor(generators.Wormhole, cm["F"])
+	cm["F"].SetNeighbor(generators.Wormhole, cm["A"])
+
+	assert.Equal([]int{generators.Wormhole, generators.North}, ShortestPath(cm["A"], cm["C"]))
+	assert.Equal(Reach{City: cm["F"], Distance: 1, FirstStep: generators.Wormhole}, Explore(cm["A"], 1)[2])
+}